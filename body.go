@@ -0,0 +1,186 @@
+package fakehttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BodyMatcher - a condition on the body of the incoming request
+type BodyMatcher struct {
+	Kind     string
+	Key      string
+	Expected interface{}
+	match    func(body []byte, header http.Header) bool
+}
+
+func (m *BodyMatcher) String() string {
+	if m.Key != "" {
+		return fmt.Sprintf("%s %s=%v", m.Kind, m.Key, m.Expected)
+	}
+	return fmt.Sprintf("%s %v", m.Kind, m.Expected)
+}
+
+func exactBodyMatcher(expected string) *BodyMatcher {
+	return &BodyMatcher{
+		Kind:     "body",
+		Expected: expected,
+		match: func(body []byte, header http.Header) bool {
+			return string(body) == expected
+		},
+	}
+}
+
+func jsonBodyMatcher(v interface{}) *BodyMatcher {
+	expected := normalizeJSON(v)
+	return &BodyMatcher{
+		Kind:     "json",
+		Expected: expected,
+		match: func(body []byte, header http.Header) bool {
+			var actual interface{}
+			if err := json.Unmarshal(body, &actual); err != nil {
+				return false
+			}
+			return reflect.DeepEqual(actual, expected)
+		},
+	}
+}
+
+func jsonPathMatcher(expr string, v interface{}) *BodyMatcher {
+	expected := normalizeJSON(jsonValue(v))
+	return &BodyMatcher{
+		Kind:     "jsonPath",
+		Key:      expr,
+		Expected: expected,
+		match: func(body []byte, header http.Header) bool {
+			var doc interface{}
+			if err := json.Unmarshal(body, &doc); err != nil {
+				return false
+			}
+			actual, ok := jsonPath(doc, expr)
+			return ok && reflect.DeepEqual(actual, expected)
+		},
+	}
+}
+
+func formValueMatcher(key string, expected string) *BodyMatcher {
+	return &BodyMatcher{
+		Kind:     "form",
+		Key:      key,
+		Expected: expected,
+		match: func(body []byte, header http.Header) bool {
+			for _, v := range formValues(body, header)[key] {
+				if v == expected {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+func regexBodyMatcher(expr string) *BodyMatcher {
+	re := regexp.MustCompile(expr)
+	return &BodyMatcher{
+		Kind:     "regex",
+		Expected: expr,
+		match: func(body []byte, header http.Header) bool {
+			return re.Match(body)
+		},
+	}
+}
+
+// normalizeJSON turns v into the generic form encoding/json decodes to, so values compare semantically;
+// strings and byte slices are taken to be JSON text already
+func normalizeJSON(v interface{}) interface{} {
+	var raw []byte
+	switch t := v.(type) {
+	case string:
+		raw = []byte(t)
+	case []byte:
+		raw = t
+	default:
+		raw = jsonValue(v)
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		panic(fmt.Sprintf("fakehttp: invalid expected JSON %q: %v", raw, err))
+	}
+	return normalized
+}
+
+// jsonValue marshals v so that even a plain string is compared as a JSON value
+func jsonValue(v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("fakehttp: cannot marshal expected JSON: %v", err))
+	}
+	return b
+}
+
+// jsonPath resolves a simple path such as $.items[0].name or items.0.name against a decoded JSON document
+func jsonPath(doc interface{}, expr string) (interface{}, bool) {
+	expr = strings.TrimPrefix(strings.TrimPrefix(expr, "$"), ".")
+	expr = strings.Replace(strings.Replace(expr, "[", ".", -1), "]", "", -1)
+	if expr == "" {
+		return doc, true
+	}
+	current := doc
+	for _, key := range strings.Split(expr, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			v, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			current = v
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			current = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// formValues parses a url-encoded or multipart form body
+func formValues(body []byte, header http.Header) url.Values {
+	mediaType, params, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		form, err := multipart.NewReader(bytes.NewReader(body), params["boundary"]).ReadForm(32 << 20)
+		if err != nil {
+			return url.Values{}
+		}
+		defer form.RemoveAll()
+		return url.Values(form.Value)
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return url.Values{}
+	}
+	return values
+}
+
+// readBody reads the whole body of r and puts an identical, unread body back in its place
+func readBody(r *http.Request) []byte {
+	if r.Body == nil {
+		return []byte{}
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body
+}
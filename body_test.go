@@ -0,0 +1,118 @@
+package fakehttp_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+var _ = Describe("Body Matcher Tests", func() {
+	var server *HTTPFake
+
+	BeforeEach(func() {
+		server = Server()
+		server.Start("127.0.0.1", "0")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	post := func(contentType string, body string) (int, string) {
+		res, err := http.Post(server.ResolveURL("/orders"), contentType, strings.NewReader(body))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		b, _ := ioutil.ReadAll(res.Body)
+		return res.StatusCode, string(b)
+	}
+
+	It("should route on the exact body", func() {
+		server.NewHandler(false).Post("/orders").WithBody("one").Reply(200).BodyString("ONE")
+		server.NewHandler(false).Post("/orders").WithBody("two").Reply(200).BodyString("TWO")
+
+		_, body := post("text/plain", "two")
+		Ω(body).Should(Equal("TWO"))
+		status, _ := post("text/plain", "three")
+		Ω(status).Should(Equal(404))
+	})
+
+	It("should compare JSON bodies semantically", func() {
+		type order struct {
+			Item     string `json:"item"`
+			Quantity int    `json:"quantity"`
+		}
+		server.NewHandler(false).Post("/orders").WithJSONBody(order{Item: "book", Quantity: 2}).Reply(201)
+		server.NewHandler(false).Post("/orders").WithJSONBody(`{"item": "pen", "quantity": 1}`).Reply(202)
+
+		status, _ := post("application/json", "{\n  \"quantity\": 2,\n  \"item\": \"book\"\n}")
+		Ω(status).Should(Equal(201))
+		status, _ = post("application/json", `{"quantity":1,"item":"pen"}`)
+		Ω(status).Should(Equal(202))
+		status, _ = post("application/json", `{"quantity":3,"item":"pen"}`)
+		Ω(status).Should(Equal(404))
+	})
+
+	It("should route on a value found by JSON path", func() {
+		server.NewHandler(false).Post("/orders").WithJSONPath("$.items[1].sku", "B-2").Reply(200).BodyString("B")
+		server.NewHandler(false).Post("/orders").WithJSONPath("customer.vip", true).Reply(200).BodyString("VIP")
+
+		_, body := post("application/json", `{"items": [{"sku": "A-1"}, {"sku": "B-2"}]}`)
+		Ω(body).Should(Equal("B"))
+		_, body = post("application/json", `{"customer": {"vip": true}}`)
+		Ω(body).Should(Equal("VIP"))
+		status, _ := post("application/json", `{"items": [{"sku": "A-1"}]}`)
+		Ω(status).Should(Equal(404))
+	})
+
+	It("should route on url-encoded form values", func() {
+		server.NewHandler(false).Post("/orders").WithFormValue("item", "book").Reply(200).BodyString("BOOK")
+
+		_, body := post("application/x-www-form-urlencoded", url.Values{"item": {"book"}, "qty": {"1"}}.Encode())
+		Ω(body).Should(Equal("BOOK"))
+		status, _ := post("application/x-www-form-urlencoded", "item=pen")
+		Ω(status).Should(Equal(404))
+	})
+
+	It("should route on multipart form values", func() {
+		server.NewHandler(false).Post("/orders").WithFormValue("item", "book").Reply(200).BodyString("BOOK")
+
+		buf := &bytes.Buffer{}
+		mw := multipart.NewWriter(buf)
+		mw.WriteField("item", "book")
+		mw.Close()
+
+		_, body := post(mw.FormDataContentType(), buf.String())
+		Ω(body).Should(Equal("BOOK"))
+	})
+
+	It("should route on a body regular expression", func() {
+		server.NewHandler(false).Post("/orders").WithBodyRegex(`"id":\s*\d+`).Reply(200)
+
+		status, _ := post("application/json", `{"id": 12}`)
+		Ω(status).Should(Equal(200))
+		status, _ = post("application/json", `{"id": "x"}`)
+		Ω(status).Should(Equal(404))
+	})
+
+	It("should leave the body readable by the Responder", func() {
+		server.NewHandler(false).Post("/orders").WithBody("ping").Handle(func(w http.ResponseWriter, r *http.Request, rh *Request) {
+			b, _ := ioutil.ReadAll(r.Body)
+			w.Write(append(b, []byte(" pong")...))
+		})
+
+		_, body := post("text/plain", "ping")
+		Ω(body).Should(Equal("ping pong"))
+	})
+
+	It("should panic at definition time on invalid expected JSON", func() {
+		Ω(func() { server.NewHandler(false).Post("/orders").WithJSONBody(`{not json`) }).Should(Panic())
+	})
+})
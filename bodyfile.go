@@ -0,0 +1,90 @@
+package fakehttp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// BodyFile - answer with the content of the file at path, read on every call; a relative path is resolved
+// against the FixtureDir of the server. The Content-Type is inferred from the extension unless already set
+func (r *Response) BodyFile(path string) *Response {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	r.BodyBuffer = nil
+	r.bodyReader = nil
+	r.BodyPath = path
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" && r.Header.Get("Content-Type") == "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return r
+}
+
+// BodyReader - answer with whatever body yields, streamed rather than loaded in BodyBuffer; a reader that is also
+// an io.Seeker is rewound for every call, any other one is drained by the first call
+func (r *Response) BodyReader(body io.Reader) *Response {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	r.BodyBuffer = nil
+	r.BodyPath = ""
+	r.bodyReader = body
+	r.streamMu = &sync.Mutex{}
+	return r
+}
+
+// FixtureDir set the directory relative BodyFile paths are resolved against
+func (f *HTTPFake) FixtureDir(dir string) *HTTPFake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fixtureDir = dir
+	return f
+}
+
+func (f *HTTPFake) fixtures() string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.fixtureDir
+}
+
+// openBody opens the body of the response: its file resolved against dir, its reader or its BodyBuffer;
+// done must be called once the body has been written
+func (r *Response) openBody(dir string) (body io.Reader, done func(), err error) {
+	switch {
+	case r.BodyPath != "":
+		path := r.BodyPath
+		if dir != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fakehttp: cannot open body file: %v", err)
+		}
+		return file, func() { file.Close() }, nil
+	case r.bodyReader != nil:
+		r.streamMu.Lock()
+		if seeker, ok := r.bodyReader.(io.Seeker); ok {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				r.streamMu.Unlock()
+				return nil, nil, fmt.Errorf("fakehttp: cannot rewind body reader: %v", err)
+			}
+		}
+		return r.bodyReader, r.streamMu.Unlock, nil
+	default:
+		return bytes.NewReader(r.BodyBuffer), func() {}, nil
+	}
+}
+
+// readBody reads the whole body of the response, for responders that rewrite it
+func (r *Response) readBody(dir string) ([]byte, error) {
+	body, done, err := r.openBody(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return ioutil.ReadAll(body)
+}
@@ -0,0 +1,119 @@
+package fakehttp_test
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+var _ = Describe("Body File Tests", func() {
+	var server *HTTPFake
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "fakehttp-fixtures")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ioutil.WriteFile(filepath.Join(dir, "user.json"), []byte(`{"id": 1}`), 0644)).Should(Succeed())
+		Ω(ioutil.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello %s"), 0644)).Should(Succeed())
+		server = Server().StartAny()
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	get := func(path string) (*http.Response, string) {
+		res, err := http.Get(server.ResolveURL(path))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, err := ioutil.ReadAll(res.Body)
+		Ω(err).ShouldNot(HaveOccurred())
+		return res, string(body)
+	}
+
+	It("should answer with the content of a file, inferring its Content-Type", func() {
+		server.NewHandler(false).Get("/user").Reply(200).BodyFile(filepath.Join(dir, "user.json"))
+
+		res, body := get("/user")
+		Ω(res.StatusCode).Should(Equal(200))
+		Ω(body).Should(Equal(`{"id": 1}`))
+		Ω(res.Header.Get("Content-Type")).Should(Equal("application/json"))
+	})
+
+	It("should keep a Content-Type set explicitly", func() {
+		server.NewHandler(false).Get("/user").Reply(200).SetHeader("Content-Type", "application/vnd.user+json").BodyFile(filepath.Join(dir, "user.json"))
+
+		res, _ := get("/user")
+		Ω(res.Header.Get("Content-Type")).Should(Equal("application/vnd.user+json"))
+	})
+
+	It("should resolve relative paths against the fixture directory", func() {
+		server.FixtureDir(dir)
+		server.NewHandler(false).Get("/user").Reply(200).BodyFile("user.json")
+
+		_, body := get("/user")
+		Ω(body).Should(Equal(`{"id": 1}`))
+	})
+
+	It("should read the file on every call", func() {
+		server.NewHandler(false).Get("/user").Reply(200).BodyFile(filepath.Join(dir, "user.json"))
+		get("/user")
+
+		Ω(ioutil.WriteFile(filepath.Join(dir, "user.json"), []byte(`{"id": 2}`), 0644)).Should(Succeed())
+		_, body := get("/user")
+		Ω(body).Should(Equal(`{"id": 2}`))
+	})
+
+	It("should answer 500 when the file cannot be read", func() {
+		server.NewHandler(false).Get("/missing").Reply(200).BodyFile(filepath.Join(dir, "missing.json"))
+
+		res, body := get("/missing")
+		Ω(res.StatusCode).Should(Equal(500))
+		Ω(body).Should(ContainSubstring("cannot open body file"))
+	})
+
+	It("should inject request data into a file body with the sophisticated responder", func() {
+		server.FixtureDir(dir)
+		rh := server.NewHandler(false).Get("/hello/world")
+		rh.Reply(200).BodyFile("hello.txt")
+		rh.AddInjectionKey("path")
+		rh.Handle(SophisticatedResponder)
+
+		_, body := get("/hello/world")
+		Ω(body).Should(Equal("hello hello/world"))
+	})
+
+	It("should stream a reader, rewinding it for every call when it can", func() {
+		server.NewHandler(false).Get("/stream").Reply(200).BodyReader(strings.NewReader("streamed"))
+
+		_, first := get("/stream")
+		_, second := get("/stream")
+		Ω(first).Should(Equal("streamed"))
+		Ω(second).Should(Equal("streamed"))
+	})
+
+	It("should drain a reader that cannot be rewound", func() {
+		server.NewHandler(false).Get("/stream").Reply(200).BodyReader(io.MultiReader(strings.NewReader("once")))
+
+		_, first := get("/stream")
+		_, second := get("/stream")
+		Ω(first).Should(Equal("once"))
+		Ω(second).Should(BeEmpty())
+	})
+
+	It("should replace a file body with a string body", func() {
+		r := NewResponse().BodyFile("user.json").BodyString("inline")
+		Ω(r.BodyPath).Should(BeEmpty())
+		Ω(string(r.BodyBuffer)).Should(Equal("inline"))
+	})
+})
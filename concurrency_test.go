@@ -0,0 +1,95 @@
+package fakehttp_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+// These specs are meant to be run with `go test -race`
+var _ = Describe("Concurrency Tests", func() {
+	var server *HTTPFake
+
+	BeforeEach(func() {
+		server = Server().StartAny()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	get := func(path string) {
+		res, err := http.Get(server.ResolveURL(path))
+		Ω(err).ShouldNot(HaveOccurred())
+		ioutil.ReadAll(res.Body)
+		res.Body.Close()
+	}
+
+	run := func(workers int, work func(i int)) {
+		wg := sync.WaitGroup{}
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer GinkgoRecover()
+				work(i)
+			}(i)
+		}
+		wg.Wait()
+	}
+
+	It("should register handlers while serving requests", func() {
+		run(20, func(i int) {
+			path := fmt.Sprintf("/users/%d", i)
+			server.NewHandler(false).Get(path).Reply(200).BodyString(path)
+			get(path)
+			get("/nowhere")
+		})
+
+		Ω(server.Handlers()).Should(HaveLen(20))
+		Ω(server.Requests()).Should(HaveLen(40))
+	})
+
+	It("should reset handlers while serving requests", func() {
+		run(20, func(i int) {
+			if i%5 == 0 {
+				server.Reset()
+			}
+			server.NewHandler(false).Get("*").Reply(200)
+			get("/any")
+		})
+	})
+
+	It("should mutate a registered request while serving it", func() {
+		rh := server.NewHandler(false).Get("/users")
+		rh.Reply(200).BodyString("initial")
+
+		run(20, func(i int) {
+			rh.SetHeader("X-Worker", fmt.Sprint(i)).WithQueryMatcher("q", MatchAbsent, "").Priority(i)
+			rh.Reply(200).SetHeader("X-Worker", fmt.Sprint(i)).BodyString(fmt.Sprintf("worker %d", i))
+			rh.Handle(SophisticatedResponder)
+			get("/users")
+			rh.Cookies()
+			rh.CallCount()
+		})
+
+		Ω(rh.CallCount()).Should(Equal(20))
+	})
+
+	It("should verify while serving requests", func() {
+		server.NewHandler(false).Get("/users").AtLeast(1).Reply(200)
+
+		run(20, func(i int) {
+			get("/users")
+			server.Verify()
+		})
+
+		Ω(server.Verify()).Should(Succeed())
+	})
+})
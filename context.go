@@ -0,0 +1,29 @@
+package fakehttp
+
+import (
+	"context"
+	"net/http"
+)
+
+// CallContext - per-call data gathered while routing an incoming http.Request to a Request handler
+type CallContext struct {
+	Handler    *Request
+	Params     map[string]string
+	Body       []byte
+	Response   *Response
+	FixtureDir string
+}
+
+type callContextKey struct{}
+
+// Call - retrieve the CallContext of an incoming http.Request (empty when the request was not routed by HTTPFake)
+func Call(r *http.Request) *CallContext {
+	if c, ok := r.Context().Value(callContextKey{}).(*CallContext); ok {
+		return c
+	}
+	return &CallContext{Params: map[string]string{}}
+}
+
+func withCall(r *http.Request, c *CallContext) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), callContextKey{}, c))
+}
@@ -0,0 +1,20 @@
+package fakehttp_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+var _ = Describe("CallContext Tests", func() {
+	It("should return an empty CallContext for a request not routed by the fake", func() {
+		httpRequest, _ := http.NewRequest("GET", "http://example.com/users/1", nil)
+		c := Call(httpRequest)
+		Ω(c).ShouldNot(BeNil())
+		Ω(c.Handler).Should(BeNil())
+		Ω(c.Params).Should(BeEmpty())
+	})
+})
@@ -0,0 +1,67 @@
+package fakehttp
+
+import (
+	"fmt"
+	"sync"
+)
+
+// callLog - the requests routed to a single Request handler, with the expected call count bounds
+// (max is negative while there is no upper bound)
+type callLog struct {
+	mu       sync.Mutex
+	calls    []*RecordedRequest
+	min, max int
+	expected bool
+}
+
+func newCallLog() *callLog {
+	return &callLog{max: -1}
+}
+
+func (l *callLog) record(rr *RecordedRequest) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls = append(l.calls, rr)
+}
+
+func (l *callLog) all() []*RecordedRequest {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*RecordedRequest{}, l.calls...)
+}
+
+// expectAtLeast sets the lower bound, keeping the upper one
+func (l *callLog) expectAtLeast(min int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.min, l.expected = min, true
+}
+
+// expectAtMost sets the upper bound, keeping the lower one
+func (l *callLog) expectAtMost(max int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.max, l.expected = max, true
+}
+
+// unmet returns a description of the unmet expectation, or "" when it is satisfied (or there is none)
+func (l *callLog) unmet() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := len(l.calls)
+	if !l.expected || (n >= l.min && (l.max < 0 || n <= l.max)) {
+		return ""
+	}
+	var want string
+	switch {
+	case l.min == l.max:
+		want = fmt.Sprintf("exactly %d", l.min)
+	case l.max < 0:
+		want = fmt.Sprintf("at least %d", l.min)
+	case l.min == 0:
+		want = fmt.Sprintf("at most %d", l.max)
+	default:
+		want = fmt.Sprintf("between %d and %d", l.min, l.max)
+	}
+	return fmt.Sprintf("expected %s call(s), got %d", want, n)
+}
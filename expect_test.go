@@ -0,0 +1,108 @@
+package fakehttp_test
+
+import (
+	"net/http"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+var _ = Describe("Call Expectation Tests", func() {
+	var server *HTTPFake
+
+	BeforeEach(func() {
+		server = Server()
+		server.Start("127.0.0.1", "0")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	call := func(method string, path string) {
+		req, _ := http.NewRequest(method, server.ResolveURL(path), nil)
+		res, err := http.DefaultClient.Do(req)
+		Ω(err).ShouldNot(HaveOccurred())
+		res.Body.Close()
+	}
+
+	It("should count the calls routed to a handler", func() {
+		payments := server.NewHandler(false).Post("/payments")
+		payments.Reply(201)
+
+		Ω(payments.CallCount()).Should(BeZero())
+		call("POST", "/payments")
+		call("POST", "/payments")
+		Ω(payments.CallCount()).Should(Equal(2))
+		Ω(payments.Calls()[0].Method).Should(Equal("POST"))
+	})
+
+	It("should verify cleanly when there are no expectations", func() {
+		server.NewHandler(false).Get("/users").Reply(200)
+		call("GET", "/users")
+		Ω(server.Verify()).Should(Succeed())
+	})
+
+	It("should verify an exact call count", func() {
+		server.NewHandler(false).Post("/payments").Times(1).Reply(201)
+
+		Ω(server.Verify()).Should(MatchError("fakehttp: 1 unmet expectation(s):\n  POST /payments/: expected exactly 1 call(s), got 0"))
+		call("POST", "/payments")
+		Ω(server.Verify()).Should(Succeed())
+		call("POST", "/payments")
+		Ω(server.Verify()).Should(MatchError(ContainSubstring("expected exactly 1 call(s), got 2")))
+	})
+
+	It("should verify at least and at most", func() {
+		server.NewHandler(false).Get("/a").AtLeast(2).Reply(200)
+		server.NewHandler(false).Get("/b").AtMost(1).Reply(200)
+
+		call("GET", "/a")
+		call("GET", "/b")
+		call("GET", "/b")
+
+		err := server.Verify()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("fakehttp: 2 unmet expectation(s)"))
+		Ω(err.Error()).Should(ContainSubstring("GET /a/: expected at least 2 call(s), got 1"))
+		Ω(err.Error()).Should(ContainSubstring("GET /b/: expected at most 1 call(s), got 2"))
+	})
+
+	It("should combine at least and at most", func() {
+		rh := server.NewHandler(false).Get("/range").AtLeast(1).AtMost(3)
+		rh.Reply(200)
+		Ω(server.Verify()).Should(MatchError(ContainSubstring("GET /range/: expected between 1 and 3 call(s), got 0")))
+
+		call("GET", "/range")
+		Ω(server.Verify()).Should(Succeed())
+
+		rh.AtLeast(2)
+		Ω(server.Verify()).Should(MatchError(ContainSubstring("expected between 2 and 3 call(s), got 1")))
+
+		call("GET", "/range")
+		call("GET", "/range")
+		call("GET", "/range")
+		Ω(server.Verify()).Should(MatchError(ContainSubstring("expected between 2 and 3 call(s), got 4")))
+	})
+
+	It("should record the calls of a Request built as a literal", func() {
+		rh := &Request{Method: "GET", URL: &url.URL{Path: "/literal/"}, Header: http.Header{}, Response: &Response{StatusCode: 200}}
+		rh.Times(1)
+		server.RequestHandlers = append(server.RequestHandlers, rh)
+
+		call("GET", "/literal")
+		Ω(rh.CallCount()).Should(Equal(1))
+		Ω(server.Verify()).Should(Succeed())
+	})
+
+	It("should verify that a handler is never called", func() {
+		server.NewHandler(false).Delete("/users").Never().Reply(204)
+		Ω(server.Verify()).Should(Succeed())
+
+		call("DELETE", "/users")
+		Ω(server.Verify()).Should(MatchError(ContainSubstring("expected exactly 0 call(s), got 1")))
+	})
+})
@@ -0,0 +1,154 @@
+package fakehttp
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// Fault - a transport failure the fake server simulates instead of answering normally
+type Fault int
+
+const (
+	// NoFault - answer normally
+	NoFault Fault = iota
+	// FaultConnectionReset - reset the connection (TCP RST) without answering
+	FaultConnectionReset
+	// FaultCloseAfterHeaders - send the status line and headers, then close the connection before the body
+	FaultCloseAfterHeaders
+	// FaultMalformedResponse - answer with bytes that are not HTTP
+	FaultMalformedResponse
+	// FaultEmptyReply - close the connection without sending anything
+	FaultEmptyReply
+	// FaultTruncatedBody - announce the full Content-Length, send only part of the body and close the connection
+	FaultTruncatedBody
+)
+
+func (f Fault) String() string {
+	switch f {
+	case FaultConnectionReset:
+		return "connection reset"
+	case FaultCloseAfterHeaders:
+		return "close after headers"
+	case FaultMalformedResponse:
+		return "malformed response"
+	case FaultEmptyReply:
+		return "empty reply"
+	case FaultTruncatedBody:
+		return "truncated body"
+	default:
+		return "no fault"
+	}
+}
+
+// ResetConnection - reset the connection instead of answering
+func (r *Response) ResetConnection() *Response {
+	return r.fault(FaultConnectionReset, 0)
+}
+
+// CloseAfterHeaders - send the status and headers, then close the connection without a body
+func (r *Response) CloseAfterHeaders() *Response {
+	return r.fault(FaultCloseAfterHeaders, 0)
+}
+
+// MalformedResponse - answer with garbage that no HTTP client can parse
+func (r *Response) MalformedResponse() *Response {
+	return r.fault(FaultMalformedResponse, 0)
+}
+
+// EmptyReply - close the connection without sending a single byte
+func (r *Response) EmptyReply() *Response {
+	return r.fault(FaultEmptyReply, 0)
+}
+
+// TruncateBody - announce the Content-Length of the whole body but close the connection after n bytes of it
+func (r *Response) TruncateBody(n int) *Response {
+	return r.fault(FaultTruncatedBody, n)
+}
+
+func (r *Response) fault(fault Fault, truncateAt int) *Response {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	r.Fault = fault
+	r.TruncateAt = truncateAt
+	return r
+}
+
+// malformedResponse - what FaultMalformedResponse sends
+const malformedResponse = "NOT-HTTP \x00\x01\x02 garbage\r\n\r\n"
+
+// injectFault hijacks the connection behind w to simulate the fault of response; connections that cannot be
+// hijacked, such as HTTP/2 streams, are aborted instead
+func injectFault(w http.ResponseWriter, r *http.Request, response *Response) {
+	if !wait(r, response.delay()) {
+		return
+	}
+	var body []byte
+	if response.Fault == FaultCloseAfterHeaders || response.Fault == FaultTruncatedBody {
+		var err error
+		body, err = response.readBody(Call(r).FixtureDir)
+		if err == nil && response.Templated {
+			body, err = renderTemplate(body, r)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("--- 500 " + err.Error()))
+			return
+		}
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	defer conn.Close()
+
+	switch response.Fault {
+	case FaultConnectionReset:
+		resetOnClose(conn)
+	case FaultCloseAfterHeaders:
+		writeRawHead(conn, response, len(body))
+	case FaultMalformedResponse:
+		conn.Write([]byte(malformedResponse))
+	case FaultTruncatedBody:
+		writeRawHead(conn, response, len(body))
+		n := response.TruncateAt
+		if n < 0 {
+			n = 0
+		}
+		if n > len(body) {
+			n = len(body)
+		}
+		conn.Write(body[:n])
+	}
+}
+
+// writeRawHead writes the status line and headers of response, announcing a body of contentLength bytes
+func writeRawHead(conn net.Conn, response *Response, contentLength int) {
+	status := response.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	header := response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Length", strconv.Itoa(contentLength))
+	fmt.Fprintf(conn, "HTTP/1.1 %d %s\r\n", status, http.StatusText(status))
+	header.Write(conn)
+	conn.Write([]byte("\r\n"))
+}
+
+// resetOnClose makes closing conn send a TCP RST rather than a graceful FIN
+func resetOnClose(conn net.Conn) {
+	if wrapper, ok := conn.(interface{ NetConn() net.Conn }); ok {
+		conn = wrapper.NetConn()
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.SetLinger(0)
+	}
+}
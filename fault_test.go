@@ -0,0 +1,126 @@
+package fakehttp_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+var _ = Describe("Fault Injection Tests", func() {
+	var server *HTTPFake
+	var client *http.Client
+
+	BeforeEach(func() {
+		server = Server().StartAny()
+		client = &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should reset the connection", func() {
+		server.NewHandler(false).Get("/reset").Reply(200).ResetConnection()
+
+		_, err := client.Get(server.ResolveURL("/reset"))
+		Ω(err).Should(HaveOccurred())
+		Ω(server.Requests()).Should(HaveLen(1))
+	})
+
+	It("should close the connection after the headers", func() {
+		server.NewHandler(false).Get("/headers").Reply(200).SetHeader("X-Fault", "yes").BodyString("never sent").CloseAfterHeaders()
+
+		res, err := client.Get(server.ResolveURL("/headers"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		Ω(res.StatusCode).Should(Equal(200))
+		Ω(res.Header.Get("X-Fault")).Should(Equal("yes"))
+		_, err = ioutil.ReadAll(res.Body)
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("should answer with a malformed response", func() {
+		server.NewHandler(false).Get("/garbage").Reply(200).MalformedResponse()
+
+		_, err := client.Get(server.ResolveURL("/garbage"))
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("malformed HTTP"))
+	})
+
+	It("should close the connection without replying", func() {
+		server.NewHandler(false).Get("/empty").Reply(200).EmptyReply()
+
+		_, err := client.Get(server.ResolveURL("/empty"))
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("EOF"))
+	})
+
+	It("should truncate the body while announcing its full length", func() {
+		server.NewHandler(false).Get("/truncated").Reply(200).BodyString("0123456789").TruncateBody(4)
+
+		res, err := client.Get(server.ResolveURL("/truncated"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		Ω(res.ContentLength).Should(Equal(int64(10)))
+		body, err := ioutil.ReadAll(res.Body)
+		Ω(err).Should(HaveOccurred())
+		Ω(string(body)).Should(Equal("0123"))
+	})
+
+	It("should truncate a body read from a file", func() {
+		file, err := ioutil.TempFile("", "fakehttp-fault")
+		Ω(err).ShouldNot(HaveOccurred())
+		defer os.Remove(file.Name())
+		file.WriteString("0123456789")
+		file.Close()
+		server.NewHandler(false).Get("/truncated").Reply(200).BodyFile(file.Name()).TruncateBody(4)
+
+		res, err := client.Get(server.ResolveURL("/truncated"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		Ω(res.ContentLength).Should(Equal(int64(10)))
+		body, err := ioutil.ReadAll(res.Body)
+		Ω(err).Should(HaveOccurred())
+		Ω(string(body)).Should(Equal("0123"))
+	})
+
+	It("should announce the length of a streamed body before closing after the headers", func() {
+		server.NewHandler(false).Get("/headers").Reply(200).BodyReader(strings.NewReader("never sent")).CloseAfterHeaders()
+
+		res, err := client.Get(server.ResolveURL("/headers"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		Ω(res.ContentLength).Should(Equal(int64(10)))
+		_, err = ioutil.ReadAll(res.Body)
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("should fail only the steps of a sequence that inject a fault", func() {
+		server.NewHandler(false).Get("/flaky").ReplyOnce(200).EmptyReply().Then(200).BodyString("OK")
+
+		_, err := client.Get(server.ResolveURL("/flaky"))
+		Ω(err).Should(HaveOccurred())
+
+		res, err := client.Get(server.ResolveURL("/flaky"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal("OK"))
+	})
+
+	It("should abort HTTP/2 streams it cannot hijack", func() {
+		tlsServer := Server().EnableHTTP2()
+		Ω(tlsServer.ListenTLS("127.0.0.1", "0")).Should(Succeed())
+		defer tlsServer.Close()
+		tlsServer.NewHandler(false).Get("/reset").Reply(200).ResetConnection()
+
+		_, err := tlsServer.Client().Get(tlsServer.ResolveURL("/reset"))
+		Ω(err).Should(HaveOccurred())
+	})
+})
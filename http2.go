@@ -0,0 +1,37 @@
+package fakehttp
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// EnableHTTP2 serve HTTP/2 to clients negotiating it over TLS; call before StartTLS
+func (f *HTTPFake) EnableHTTP2() *HTTPFake {
+	f.server.EnableHTTP2 = true
+	return f
+}
+
+// EnableH2C serve cleartext HTTP/2 (h2c) alongside HTTP/1.1; call before Start
+func (f *HTTPFake) EnableH2C() *HTTPFake {
+	if !f.h2c {
+		f.server.Config.Handler = h2c.NewHandler(f.server.Config.Handler, &http2.Server{})
+		f.h2c = true
+	}
+	return f
+}
+
+// h2cClient - a client speaking HTTP/2 with prior knowledge over an unencrypted connection
+func h2cClient(dial func(network, addr string) (net.Conn, error)) *http.Client {
+	return &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return dial(network, addr)
+			},
+		},
+	}
+}
@@ -0,0 +1,65 @@
+package fakehttp_test
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+var _ = Describe("HTTP/2 Tests", func() {
+	var server *HTTPFake
+
+	BeforeEach(func() {
+		server = Server()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	get := func(client *http.Client) *http.Response {
+		res, err := client.Get(server.ResolveURL("/users"))
+		Ω(err).ShouldNot(HaveOccurred())
+		ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		return res
+	}
+
+	It("should serve HTTP/2 over TLS", func() {
+		server.EnableHTTP2().StartTLS("127.0.0.1", "0")
+		server.NewHandler(false).Get("/users").Reply(200)
+
+		res := get(server.Client())
+		Ω(res.ProtoMajor).Should(Equal(2))
+		Ω(server.Requests()[0].Proto).Should(Equal("HTTP/2.0"))
+	})
+
+	It("should serve HTTP/1.1 over TLS unless HTTP/2 is enabled", func() {
+		server.StartTLS("127.0.0.1", "0")
+		server.NewHandler(false).Get("/users").Reply(200)
+
+		get(server.Client())
+		Ω(server.Requests()[0].Proto).Should(Equal("HTTP/1.1"))
+	})
+
+	It("should serve cleartext HTTP/2", func() {
+		server.EnableH2C().StartAny()
+		server.NewHandler(false).Get("/users").Reply(200)
+
+		res := get(server.Client())
+		Ω(res.ProtoMajor).Should(Equal(2))
+		Ω(server.Requests()[0].Proto).Should(Equal("HTTP/2.0"))
+	})
+
+	It("should still serve HTTP/1.1 when h2c is enabled", func() {
+		server.EnableH2C().StartAny()
+		server.NewHandler(false).Get("/users").Reply(200)
+
+		get(&http.Client{})
+		Ω(server.Requests()[0].Proto).Should(Equal("HTTP/1.1"))
+	})
+})
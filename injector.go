@@ -0,0 +1,117 @@
+package fakehttp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Injector - computes the value an injection key inserts into the Body of a Response
+type Injector func(r *http.Request) string
+
+// paramInjector - computes the value of a parameterized injection key such as "query:<name>"
+type paramInjector func(r *http.Request, name string) string
+
+var (
+	injectorsMu = &sync.RWMutex{}
+	injectors   = map[string]Injector{
+		"path": func(r *http.Request) string {
+			return strings.TrimPrefix(r.URL.Path, "/")
+		},
+		"method": func(r *http.Request) string {
+			return r.Method
+		},
+		"host": func(r *http.Request) string {
+			return r.Host
+		},
+		"body": func(r *http.Request) string {
+			if body := Call(r).Body; body != nil {
+				return string(body)
+			}
+			return string(readBody(r))
+		},
+	}
+	paramInjectors = map[string]paramInjector{
+		"query": func(r *http.Request, name string) string {
+			return r.URL.Query().Get(name)
+		},
+		"header": func(r *http.Request, name string) string {
+			return r.Header.Get(name)
+		},
+		"cookie": func(r *http.Request, name string) string {
+			if c, err := r.Cookie(name); err == nil {
+				return c.Value
+			}
+			return ""
+		},
+		"param": func(r *http.Request, name string) string {
+			return Call(r).Params[name]
+		},
+	}
+)
+
+// RegisterInjector - make name usable as an injection key, replacing any injector already registered under it
+func RegisterInjector(name string, injector func(*http.Request) string) {
+	injectorsMu.Lock()
+	defer injectorsMu.Unlock()
+	injectors[name] = injector
+}
+
+// injectionValue computes the value of key, looking up registered injectors before the parameterized built-ins;
+// the injector runs once the registry is unlocked, so it may itself register injectors
+func injectionValue(key string, r *http.Request) (string, error) {
+	injectorsMu.RLock()
+	injector, ok := injectors[key]
+	var param paramInjector
+	var name string
+	if i := strings.Index(key, ":"); !ok && i > 0 {
+		param, name = paramInjectors[key[:i]], key[i+1:]
+	}
+	injectorsMu.RUnlock()
+	switch {
+	case ok:
+		return injector(r), nil
+	case param != nil:
+		return param(r, name), nil
+	}
+	return "", fmt.Errorf("fakehttp: unknown injection key %q", key)
+}
+
+// inject substitutes the values of keys, in order, for the %s placeholders of body; %% stands for a literal
+// percent sign and any other % is left alone. A body with more or fewer placeholders than keys is an error
+func inject(body string, keys []string, r *http.Request) (string, error) {
+	values := []string{}
+	for _, k := range keys {
+		v, err := injectionValue(k, r)
+		if err != nil {
+			return "", err
+		}
+		values = append(values, v)
+	}
+	var out strings.Builder
+	placeholders := 0
+	for i := 0; i < len(body); i++ {
+		if body[i] != '%' || i+1 == len(body) {
+			out.WriteByte(body[i])
+			continue
+		}
+		switch body[i+1] {
+		case 's':
+			if placeholders < len(values) {
+				out.WriteString(values[placeholders])
+			}
+			placeholders++
+			i++
+		case '%':
+			out.WriteByte('%')
+			i++
+		default:
+			out.WriteByte('%')
+		}
+	}
+	if placeholders != len(values) {
+		return "", fmt.Errorf("fakehttp: body has %d %%s placeholder(s) for %d injection key(s)", placeholders, len(values))
+	}
+	return out.String(), nil
+}
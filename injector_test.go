@@ -0,0 +1,109 @@
+package fakehttp_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+var _ = Describe("Injector Tests", func() {
+	var server *HTTPFake
+
+	BeforeEach(func() {
+		server = Server().StartAny()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	send := func(req *http.Request) (int, string) {
+		res, err := http.DefaultClient.Do(req)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		return res.StatusCode, string(body)
+	}
+
+	It("should inject every built-in key in order", func() {
+		fakeRequest := server.NewHandler(false).Post("/orders/{id}").
+			AddInjectionKey("method").AddInjectionKey("host").AddInjectionKey("query:q").
+			AddInjectionKey("header:X-Trace").AddInjectionKey("cookie:session").
+			AddInjectionKey("param:id").AddInjectionKey("body")
+		fakeRequest.Reply(200).BodyString("%s|%s|%s|%s|%s|%s|%s")
+		fakeRequest.Handle(SophisticatedResponder)
+
+		req, _ := http.NewRequest("POST", server.ResolveURL("/orders/7?q=find"), strings.NewReader("payload"))
+		req.Header.Set("X-Trace", "t-1")
+		req.AddCookie(&http.Cookie{Name: "session", Value: "s-1"})
+		status, body := send(req)
+		Ω(status).Should(Equal(200))
+		Ω(body).Should(Equal("POST|" + req.URL.Host + "|find|t-1|s-1|7|payload"))
+	})
+
+	It("should inject the value of a registered injector", func() {
+		RegisterInjector("test:agent", func(r *http.Request) string {
+			return strings.ToUpper(r.Header.Get("X-Agent"))
+		})
+		fakeRequest := server.NewHandler(false).Get("/agent").AddInjectionKey("test:agent")
+		fakeRequest.Reply(200).BodyString("agent %s")
+		fakeRequest.Handle(SophisticatedResponder)
+
+		req, _ := http.NewRequest("GET", server.ResolveURL("/agent"), nil)
+		req.Header.Set("X-Agent", "probe")
+		_, body := send(req)
+		Ω(body).Should(Equal("agent PROBE"))
+	})
+
+	It("should answer 500 for an unknown injection key", func() {
+		fakeRequest := server.NewHandler(false).Get("/unknown").AddInjectionKey("nope")
+		fakeRequest.Reply(200).BodyString("value %s")
+		fakeRequest.Handle(SophisticatedResponder)
+
+		req, _ := http.NewRequest("GET", server.ResolveURL("/unknown"), nil)
+		status, body := send(req)
+		Ω(status).Should(Equal(500))
+		Ω(body).Should(ContainSubstring(`unknown injection key "nope"`))
+	})
+
+	It("should leave literal percent signs alone", func() {
+		fakeRequest := server.NewHandler(false).Get("/discount/{id}").AddInjectionKey("param:id")
+		fakeRequest.Reply(200).BodyString(`{"id":"%s","discount":"10%"}`)
+		fakeRequest.Handle(SophisticatedResponder)
+
+		req, _ := http.NewRequest("GET", server.ResolveURL("/discount/7"), nil)
+		status, body := send(req)
+		Ω(status).Should(Equal(200))
+		Ω(body).Should(Equal(`{"id":"7","discount":"10%"}`))
+	})
+
+	It("should answer 500 when the placeholders do not match the injection keys", func() {
+		fakeRequest := server.NewHandler(false).Get("/mismatch").AddInjectionKey("method").AddInjectionKey("host")
+		fakeRequest.Reply(200).BodyString("only %s")
+		fakeRequest.Handle(SophisticatedResponder)
+
+		req, _ := http.NewRequest("GET", server.ResolveURL("/mismatch"), nil)
+		status, body := send(req)
+		Ω(status).Should(Equal(500))
+		Ω(body).Should(ContainSubstring("body has 1 %s placeholder(s) for 2 injection key(s)"))
+	})
+
+	It("should let an injector register another one", func() {
+		RegisterInjector("test:outer", func(r *http.Request) string {
+			RegisterInjector("test:inner", func(r *http.Request) string { return "inner" })
+			return "outer"
+		})
+		fakeRequest := server.NewHandler(false).Get("/nested").AddInjectionKey("test:outer")
+		fakeRequest.Reply(200).BodyString("%s")
+		fakeRequest.Handle(SophisticatedResponder)
+
+		req, _ := http.NewRequest("GET", server.ResolveURL("/nested"), nil)
+		_, body := send(req)
+		Ω(body).Should(Equal("outer"))
+	})
+})
@@ -0,0 +1,72 @@
+package fakehttp
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// RecordedRequest - an incoming http.Request as received by the fake server
+type RecordedRequest struct {
+	Method    string
+	URL       *url.URL
+	Proto     string
+	Header    http.Header
+	Cookies   []*http.Cookie
+	Body      []byte
+	Timestamp time.Time
+	Matched   *Request
+}
+
+func newRecordedRequest(r *http.Request, body []byte, matched *Request) *RecordedRequest {
+	u := *r.URL
+	return &RecordedRequest{
+		Method:    r.Method,
+		URL:       &u,
+		Proto:     r.Proto,
+		Header:    r.Header.Clone(),
+		Cookies:   r.Cookies(),
+		Body:      body,
+		Timestamp: time.Now(),
+		Matched:   matched,
+	}
+}
+
+func (r *RecordedRequest) String() string {
+	s := r.Method + " " + r.URL.String()
+	if r.Matched == nil {
+		return s + " (unmatched)"
+	}
+	return s + " -> " + r.Matched.String()
+}
+
+// journal - thread-safe log of every request the fake server received
+type journal struct {
+	mu      sync.Mutex
+	entries []*RecordedRequest
+}
+
+func (j *journal) record(rr *RecordedRequest) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = append(j.entries, rr)
+}
+
+func (j *journal) filter(keep func(*RecordedRequest) bool) []*RecordedRequest {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	found := []*RecordedRequest{}
+	for _, rr := range j.entries {
+		if keep(rr) {
+			found = append(found, rr)
+		}
+	}
+	return found
+}
+
+func (j *journal) reset() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = nil
+}
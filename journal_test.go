@@ -0,0 +1,114 @@
+package fakehttp_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+var _ = Describe("Request Journal Tests", func() {
+	var server *HTTPFake
+
+	BeforeEach(func() {
+		server = Server()
+		server.Start("127.0.0.1", "0")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should start with an empty journal", func() {
+		Ω(server.Requests()).Should(BeEmpty())
+	})
+
+	It("should record every request with its details", func() {
+		users := server.NewHandler(false).Post("/users")
+		users.Reply(201)
+
+		req, _ := http.NewRequest("POST", server.ResolveURL("/users?x=1"), strings.NewReader(`{"name": "dreamer"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+		res, err := http.DefaultClient.Do(req)
+		Ω(err).ShouldNot(HaveOccurred())
+		res.Body.Close()
+
+		recorded := server.Requests()
+		Ω(recorded).Should(HaveLen(1))
+		Ω(recorded[0].Method).Should(Equal("POST"))
+		Ω(recorded[0].URL.Path).Should(Equal("/users"))
+		Ω(recorded[0].URL.Query().Get("x")).Should(Equal("1"))
+		Ω(recorded[0].Header.Get("Content-Type")).Should(Equal("application/json"))
+		Ω(recorded[0].Cookies[0].Value).Should(Equal("abc"))
+		Ω(string(recorded[0].Body)).Should(Equal(`{"name": "dreamer"}`))
+		Ω(recorded[0].Timestamp.IsZero()).Should(BeFalse())
+		Ω(recorded[0].Matched).Should(Equal(users))
+	})
+
+	It("should separate requests by handler and unmatched requests", func() {
+		users := server.NewHandler(false).Get("/users")
+		users.Reply(200)
+		orders := server.NewHandler(false).Get("/orders")
+		orders.Reply(200)
+
+		for _, path := range []string{"/users", "/orders", "/users", "/nowhere"} {
+			res, err := http.Get(server.ResolveURL(path))
+			Ω(err).ShouldNot(HaveOccurred())
+			res.Body.Close()
+		}
+
+		Ω(server.Requests()).Should(HaveLen(4))
+		Ω(server.RequestsFor(users)).Should(HaveLen(2))
+		Ω(server.RequestsFor(orders)).Should(HaveLen(1))
+		Ω(server.UnmatchedRequests()).Should(HaveLen(1))
+		Ω(server.UnmatchedRequests()[0].String()).Should(Equal("GET /nowhere (unmatched)"))
+	})
+
+	It("should keep the body readable by the Responder", func() {
+		server.NewHandler(false).Post("/echo").Handle(func(w http.ResponseWriter, r *http.Request, rh *Request) {
+			b, _ := ioutil.ReadAll(r.Body)
+			w.Write(b)
+		})
+
+		res, err := http.Post(server.ResolveURL("/echo"), "text/plain", strings.NewReader("hello"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal("hello"))
+		Ω(string(server.Requests()[0].Body)).Should(Equal("hello"))
+	})
+
+	It("should record concurrent requests", func() {
+		server.NewHandler(false).Get("*").Reply(200)
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer GinkgoRecover()
+				res, err := http.Get(server.ResolveURL("/any"))
+				Ω(err).ShouldNot(HaveOccurred())
+				res.Body.Close()
+			}()
+		}
+		wg.Wait()
+
+		Ω(server.Requests()).Should(HaveLen(20))
+	})
+
+	It("should forget recorded requests on Reset", func() {
+		res, err := http.Get(server.ResolveURL("/nowhere"))
+		Ω(err).ShouldNot(HaveOccurred())
+		res.Body.Close()
+
+		server.Reset()
+		Ω(server.Requests()).Should(BeEmpty())
+	})
+})
@@ -0,0 +1,101 @@
+package fakehttp
+
+import (
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// Delay - wait d before answering
+func (r *Response) Delay(d time.Duration) *Response {
+	return r.DelayRange(d, d)
+}
+
+// DelayRange - wait a random duration between min and max before answering (see Seed)
+func (r *Response) DelayRange(min time.Duration, max time.Duration) *Response {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	if max < min {
+		min, max = max, min
+	}
+	r.DelayMin = min
+	r.DelayMax = max
+	return r
+}
+
+// Seed - seed the randomness of DelayRange so that delays are reproducible
+func (r *Response) Seed(seed int64) *Response {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	r.rnd = rand.New(rand.NewSource(seed))
+	return r
+}
+
+// Throttle - trickle the body at bytesPerSecond instead of writing it at once
+func (r *Response) Throttle(bytesPerSecond int) *Response {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	r.BytesPerSecond = bytesPerSecond
+	return r
+}
+
+// delay draws the wait before answering, from the shared random source of the Response
+func (r *Response) delay() time.Duration {
+	if r.DelayMax <= r.DelayMin {
+		return r.DelayMin
+	}
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	if r.rnd == nil {
+		r.rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return r.DelayMin + time.Duration(r.rnd.Int63n(int64(r.DelayMax-r.DelayMin)+1))
+}
+
+// wait sleeps for d, returning false early when the client gives up on the request
+func wait(r *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// throttleInterval - how often a throttled body is flushed
+const throttleInterval = 100 * time.Millisecond
+
+// writeBody streams body, trickling it at bytesPerSecond when positive, until the client gives up
+func writeBody(w http.ResponseWriter, r *http.Request, body io.Reader, bytesPerSecond int) {
+	if bytesPerSecond <= 0 {
+		io.Copy(w, body)
+		return
+	}
+	chunk := bytesPerSecond * int(throttleInterval) / int(time.Second)
+	if chunk < 1 {
+		chunk = 1
+	}
+	interval := time.Duration(chunk) * time.Second / time.Duration(bytesPerSecond)
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, chunk)
+	for {
+		n, err := io.ReadFull(body, buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err != nil || !wait(r, interval) {
+			return
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package fakehttp_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+var _ = Describe("Latency Tests", func() {
+	var server *HTTPFake
+
+	BeforeEach(func() {
+		server = Server().StartAny()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	timed := func(client *http.Client, path string) (string, time.Duration, error) {
+		start := time.Now()
+		res, err := client.Get(server.ResolveURL(path))
+		if err != nil {
+			return "", time.Since(start), err
+		}
+		defer res.Body.Close()
+		body, err := ioutil.ReadAll(res.Body)
+		return string(body), time.Since(start), err
+	}
+
+	It("should wait before answering", func() {
+		server.NewHandler(false).Get("/slow").Reply(200).BodyString("OK").Delay(150 * time.Millisecond)
+
+		body, elapsed, err := timed(http.DefaultClient, "/slow")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(body).Should(Equal("OK"))
+		Ω(elapsed).Should(BeNumerically(">=", 150*time.Millisecond))
+	})
+
+	It("should let a client time out", func() {
+		server.NewHandler(false).Get("/slow").Reply(200).Delay(time.Second)
+
+		_, _, err := timed(&http.Client{Timeout: 50 * time.Millisecond}, "/slow")
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("should stop waiting when the client gives up", func() {
+		server.NewHandler(false).Get("/slow").Reply(200).Delay(10 * time.Second)
+
+		_, _, err := timed(&http.Client{Timeout: 50 * time.Millisecond}, "/slow")
+		Ω(err).Should(HaveOccurred())
+
+		start := time.Now()
+		server.Close()
+		Ω(time.Since(start)).Should(BeNumerically("<", 2*time.Second))
+	})
+
+	It("should wait a random duration within the range", func() {
+		server.NewHandler(false).Get("/jitter").Reply(200).Seed(42).DelayRange(50*time.Millisecond, 100*time.Millisecond)
+
+		for i := 0; i < 3; i++ {
+			_, elapsed, err := timed(http.DefaultClient, "/jitter")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(elapsed).Should(BeNumerically(">=", 50*time.Millisecond))
+			Ω(elapsed).Should(BeNumerically("<", time.Second))
+		}
+	})
+
+	It("should trickle a throttled body", func() {
+		server.NewHandler(false).Get("/trickle").Reply(200).BodyString(strings.Repeat("x", 100)).Throttle(200)
+
+		body, elapsed, err := timed(http.DefaultClient, "/trickle")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(body).Should(HaveLen(100))
+		Ω(elapsed).Should(BeNumerically(">=", 300*time.Millisecond))
+	})
+
+	It("should let a client time out while reading a throttled body", func() {
+		server.NewHandler(false).Get("/trickle").Reply(200).BodyString(strings.Repeat("x", 1000)).Throttle(100)
+
+		_, _, err := timed(&http.Client{Timeout: 200 * time.Millisecond}, "/trickle")
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("should throttle the sophisticated responder too", func() {
+		rh := server.NewHandler(false).Get("/trickle")
+		rh.Reply(200).BodyString(strings.Repeat("x", 100)).Throttle(200)
+		rh.Handle(SophisticatedResponder)
+
+		body, elapsed, err := timed(http.DefaultClient, "/trickle")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(body).Should(HaveLen(100))
+		Ω(elapsed).Should(BeNumerically(">=", 300*time.Millisecond))
+	})
+})
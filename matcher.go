@@ -0,0 +1,85 @@
+package fakehttp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MatchOp - the comparison a ValueMatcher applies to the incoming values
+type MatchOp int
+
+const (
+	// MatchExact - one of the incoming values equals the expected value
+	MatchExact MatchOp = iota
+	// MatchPresent - the key is present, whatever its value
+	MatchPresent
+	// MatchAbsent - the key is not present at all
+	MatchAbsent
+	// MatchRegex - one of the incoming values matches the expected regular expression
+	MatchRegex
+	// MatchContains - one of the incoming values contains the expected value
+	MatchContains
+)
+
+// ValueMatcher - a condition on the values sent for a single key (a query parameter or a header)
+type ValueMatcher struct {
+	Key   string
+	Op    MatchOp
+	Value string
+	regex *regexp.Regexp
+}
+
+// NewValueMatcher - create a ValueMatcher, compiling the value when op is MatchRegex
+func NewValueMatcher(key string, op MatchOp, value string) *ValueMatcher {
+	m := &ValueMatcher{Key: key, Op: op, Value: value}
+	if op == MatchRegex {
+		m.regex = regexp.MustCompile(value)
+	}
+	return m
+}
+
+func (m *ValueMatcher) matches(values []string) bool {
+	switch m.Op {
+	case MatchPresent:
+		return len(values) > 0
+	case MatchAbsent:
+		return len(values) == 0
+	case MatchRegex:
+		for _, v := range values {
+			if m.regex.MatchString(v) {
+				return true
+			}
+		}
+		return false
+	case MatchContains:
+		for _, v := range values {
+			if strings.Contains(v, m.Value) {
+				return true
+			}
+		}
+		return false
+	default:
+		for _, v := range values {
+			if v == m.Value {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func (m *ValueMatcher) String() string {
+	switch m.Op {
+	case MatchPresent:
+		return m.Key + " present"
+	case MatchAbsent:
+		return m.Key + " absent"
+	case MatchRegex:
+		return fmt.Sprintf("%s~/%s/", m.Key, m.Value)
+	case MatchContains:
+		return m.Key + " contains " + m.Value
+	default:
+		return m.Key + "=" + m.Value
+	}
+}
@@ -0,0 +1,148 @@
+// Package matchers - Gomega matchers asserting on the requests recorded by a fakehttp server
+package matchers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/onsi/gomega/types"
+
+	"github.com/khurlbut/fakehttp"
+)
+
+// HaveBeenCalled - succeeds when a *fakehttp.Request handler or *fakehttp.HTTPFake received at least one request
+func HaveBeenCalled() types.GomegaMatcher {
+	return &callMatcher{
+		description: "to have been called",
+		match: func(calls []*fakehttp.RecordedRequest) bool {
+			return len(calls) > 0
+		},
+	}
+}
+
+// HaveBeenCalledTimes - succeeds when a *fakehttp.Request handler or *fakehttp.HTTPFake received exactly n requests
+func HaveBeenCalledTimes(n int) types.GomegaMatcher {
+	return &callMatcher{
+		description: fmt.Sprintf("to have been called %d time(s)", n),
+		match: func(calls []*fakehttp.RecordedRequest) bool {
+			return len(calls) == n
+		},
+	}
+}
+
+// HaveReceivedHeader - succeeds when one of the recorded requests carried the header key with value val
+func HaveReceivedHeader(key string, val string) types.GomegaMatcher {
+	return &callMatcher{
+		description: fmt.Sprintf("to have received header %s: %s", http.CanonicalHeaderKey(key), val),
+		match: func(calls []*fakehttp.RecordedRequest) bool {
+			for _, rr := range calls {
+				for _, v := range rr.Header.Values(key) {
+					if v == val {
+						return true
+					}
+				}
+			}
+			return false
+		},
+	}
+}
+
+// HaveReceivedJSONBody - succeeds when one of the recorded requests had a JSON body semantically equal to obj
+// (a string or []byte obj is taken to be JSON text)
+func HaveReceivedJSONBody(obj interface{}) types.GomegaMatcher {
+	expected, err := normalizeJSON(obj)
+	return &callMatcher{
+		description: fmt.Sprintf("to have received JSON body %v", obj),
+		err:         err,
+		match: func(calls []*fakehttp.RecordedRequest) bool {
+			for _, rr := range calls {
+				var actual interface{}
+				if json.Unmarshal(rr.Body, &actual) == nil && reflect.DeepEqual(actual, expected) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// callMatcher - a GomegaMatcher applying match to the requests recorded for the actual value
+type callMatcher struct {
+	description string
+	err         error
+	match       func(calls []*fakehttp.RecordedRequest) bool
+}
+
+func (m *callMatcher) Match(actual interface{}) (bool, error) {
+	if m.err != nil {
+		return false, m.err
+	}
+	calls, _, err := recorded(actual)
+	if err != nil {
+		return false, err
+	}
+	return m.match(calls), nil
+}
+
+func (m *callMatcher) FailureMessage(actual interface{}) string {
+	return m.message(actual, "Expected")
+}
+
+func (m *callMatcher) NegatedFailureMessage(actual interface{}) string {
+	return m.message(actual, "Expected not")
+}
+
+func (m *callMatcher) message(actual interface{}, expected string) string {
+	calls, name, _ := recorded(actual)
+	msg := fmt.Sprintf("%s %s %s\nRecorded requests (%d):", expected, name, m.description, len(calls))
+	for _, rr := range calls {
+		msg += "\n  " + rr.String()
+		keys := make([]string, 0, len(rr.Header))
+		for k := range rr.Header {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			msg += fmt.Sprintf("\n    %s: %s", k, strings.Join(rr.Header[k], ", "))
+		}
+		if len(rr.Body) > 0 {
+			msg += "\n    " + string(rr.Body)
+		}
+	}
+	return msg
+}
+
+// recorded returns the requests recorded for a *fakehttp.Request or *fakehttp.HTTPFake, and a name for it
+func recorded(actual interface{}) ([]*fakehttp.RecordedRequest, string, error) {
+	switch a := actual.(type) {
+	case *fakehttp.Request:
+		return a.Calls(), a.String(), nil
+	case *fakehttp.HTTPFake:
+		return a.Requests(), "fake server " + a.URL(), nil
+	default:
+		return nil, "", fmt.Errorf("fakehttp matchers expect a *fakehttp.Request or *fakehttp.HTTPFake, got %T", actual)
+	}
+}
+
+func normalizeJSON(obj interface{}) (interface{}, error) {
+	var raw []byte
+	switch t := obj.(type) {
+	case string:
+		raw = []byte(t)
+	case []byte:
+		raw = t
+	default:
+		b, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		raw = b
+	}
+	var normalized interface{}
+	err := json.Unmarshal(raw, &normalized)
+	return normalized, err
+}
@@ -0,0 +1,82 @@
+package matchers_test
+
+import (
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+	. "github.com/khurlbut/fakehttp/matchers"
+)
+
+var _ = Describe("Matcher Tests", func() {
+	var server *HTTPFake
+	var users *Request
+
+	BeforeEach(func() {
+		server = Server()
+		server.Start("127.0.0.1", "0")
+		users = server.NewHandler(false).Post("/users")
+		users.Reply(201)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	post := func(body string) {
+		req, _ := http.NewRequest("POST", server.ResolveURL("/users"), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		res, err := http.DefaultClient.Do(req)
+		Ω(err).ShouldNot(HaveOccurred())
+		res.Body.Close()
+	}
+
+	It("should match a handler that has been called", func() {
+		Ω(users).ShouldNot(HaveBeenCalled())
+		post(`{}`)
+		Ω(users).Should(HaveBeenCalled())
+		Ω(server).Should(HaveBeenCalled())
+	})
+
+	It("should match the number of calls", func() {
+		post(`{}`)
+		post(`{}`)
+		Ω(users).Should(HaveBeenCalledTimes(2))
+		Ω(users).ShouldNot(HaveBeenCalledTimes(1))
+	})
+
+	It("should match a received header", func() {
+		post(`{}`)
+		Ω(users).Should(HaveReceivedHeader("content-type", "application/json"))
+		Ω(users).ShouldNot(HaveReceivedHeader("Content-Type", "text/plain"))
+	})
+
+	It("should match a received JSON body", func() {
+		post(`{"name": "dreamer", "age": 7}`)
+		Ω(users).Should(HaveReceivedJSONBody(map[string]interface{}{"age": 7, "name": "dreamer"}))
+		Ω(server).Should(HaveReceivedJSONBody(`{"age":7,"name":"dreamer"}`))
+		Ω(users).ShouldNot(HaveReceivedJSONBody(`{"name": "sleeper"}`))
+	})
+
+	It("should print the recorded requests on failure", func() {
+		post(`{"name": "dreamer"}`)
+		matcher := HaveBeenCalledTimes(3)
+		success, err := matcher.Match(users)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(success).Should(BeFalse())
+
+		msg := matcher.FailureMessage(users)
+		Ω(msg).Should(ContainSubstring("Expected POST /users/ to have been called 3 time(s)"))
+		Ω(msg).Should(ContainSubstring("Recorded requests (1):"))
+		Ω(msg).Should(ContainSubstring("POST /users -> POST /users/"))
+		Ω(msg).Should(ContainSubstring(`{"name": "dreamer"}`))
+	})
+
+	It("should error on an unsupported actual value", func() {
+		_, err := HaveBeenCalled().Match("not a fake")
+		Ω(err).Should(HaveOccurred())
+	})
+})
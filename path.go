@@ -0,0 +1,59 @@
+package fakehttp
+
+import "strings"
+
+// pathTemplate - a compiled path such as /users/{id}/orders/{orderId} or /files/**
+type pathTemplate struct {
+	segments []string
+	glob     bool
+}
+
+func isPathTemplate(p string) bool {
+	return strings.Contains(p, "{") || strings.HasSuffix(strings.TrimSuffix(p, "/"), "**")
+}
+
+func newPathTemplate(p string) *pathTemplate {
+	t := &pathTemplate{segments: splitPath(p)}
+	if n := len(t.segments); n > 0 && t.segments[n-1] == "**" {
+		t.segments = t.segments[:n-1]
+		t.glob = true
+	}
+	return t
+}
+
+// match returns the captured params when path fits the template; a trailing ** is captured under "**"
+func (t *pathTemplate) match(path string) (map[string]string, bool) {
+	parts := splitPath(path)
+	if len(parts) < len(t.segments) || (!t.glob && len(parts) != len(t.segments)) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, seg := range t.segments {
+		if name, ok := paramName(seg); ok {
+			params[name] = parts[i]
+			continue
+		}
+		if seg != parts[i] {
+			return nil, false
+		}
+	}
+	if t.glob {
+		params["**"] = strings.Join(parts[len(t.segments):], "/")
+	}
+	return params, true
+}
+
+func paramName(seg string) (string, bool) {
+	if len(seg) > 2 && strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+		return seg[1 : len(seg)-1], true
+	}
+	return "", false
+}
+
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return []string{}
+	}
+	return strings.Split(p, "/")
+}
@@ -2,12 +2,18 @@ package fakehttp
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
+	"sync"
 )
 
 // Request - fake Request object
+//
+// Its methods are safe to call while the fake server is routing requests to it; assigning the
+// exported fields directly once the Request is registered is not
 type Request struct {
 	Method           string
 	URL              *url.URL
@@ -17,20 +23,52 @@ type Request struct {
 	CustomHandle     Responder
 	InjectionKeys    []string
 	ServiceEndpoints []string
+	QueryMatchers    []*ValueMatcher
+	HeaderMatchers   []*ValueMatcher
+	BodyMatchers     []*BodyMatcher
+	PathRegex        *regexp.Regexp
+	PriorityLevel    int
+	Sequence         []*Response
+	SequenceEnd      SequenceEnd
+	ScenarioName     string
+	RequiredState    string
+	NewState         string
 	RenderHTML       bool
+	pathTemplate     *pathTemplate
+	pathRegex        *regexp.Regexp
+	calls            *callLog
+	sequenceIndex    int
+	mu               *sync.RWMutex
 }
 
 // NewRequest - create a Request object
 func NewRequest(renderHTML bool) *Request {
-	return &Request{
+	r := &Request{
 		URL:        &url.URL{},
 		Header:     make(http.Header),
 		Response:   NewResponse(),
 		RenderHTML: renderHTML,
+		calls:      newCallLog(),
+		mu:         &sync.RWMutex{},
 	}
+	r.Response.owner = r
+	return r
+}
+
+// lock - the lock of the request, created on first use when the Request was built as a literal
+func (r *Request) lock() *sync.RWMutex {
+	lazyLocks.Lock()
+	defer lazyLocks.Unlock()
+	if r.mu == nil {
+		r.mu = &sync.RWMutex{}
+	}
+	return r.mu
 }
 
 // Get - create a Get request object
+//
+// Like the other method helpers, path may be a literal path, "*" to match any path, or a template
+// such as /users/{id}/orders/{orderId} or /files/** whose captured values are available via Call(r).Params
 func (r *Request) Get(path string) *Request {
 	return r.method("GET", path)
 }
@@ -60,26 +98,116 @@ func (r *Request) Head(path string) *Request {
 	return r.method("HEAD", path)
 }
 
+// GetRegex - create a Get request object matching any path the regular expression matches
+func (r *Request) GetRegex(expr string) *Request {
+	return r.Match("GET", regexp.MustCompile(expr))
+}
+
+// Match - create a request object for method matching any path the regular expression matches
+//
+// The expression must match the whole path; named capture groups are available via Call(r).Params
+func (r *Request) Match(method string, re *regexp.Regexp) *Request {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	r.URL.Path = ""
+	r.Method = strings.ToUpper(method)
+	r.pathTemplate = nil
+	r.PathRegex = re
+	r.pathRegex = anchorRegex(re)
+	return r
+}
+
 // SetHeader - set a Header on a request object
 func (r *Request) SetHeader(key string, val string) *Request {
+	r.lock().Lock()
+	defer r.lock().Unlock()
 	r.Header.Set(key, val)
 	return r
 }
 
 // AddHeader - add a Header to a request object
 func (r *Request) AddHeader(key string, val string) *Request {
+	r.lock().Lock()
+	defer r.lock().Unlock()
 	r.Header.Add(key, val)
 	return r
 }
 
+// WithQuery - require a query parameter with the given value
+func (r *Request) WithQuery(key string, val string) *Request {
+	return r.WithQueryMatcher(key, MatchExact, val)
+}
+
+// WithQueryMatcher - require a query parameter to satisfy the given MatchOp
+func (r *Request) WithQueryMatcher(key string, op MatchOp, val string) *Request {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	r.QueryMatchers = append(r.QueryMatchers, NewValueMatcher(key, op, val))
+	return r
+}
+
+// Priority - set an explicit priority; when several handlers match, the highest priority wins before any other rule
+func (r *Request) Priority(n int) *Request {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	r.PriorityLevel = n
+	return r
+}
+
+// WithHeader - route to this request only when the incoming request carries the header with the given value
+//
+// Unlike SetHeader/AddHeader, which SophisticatedResponder validates once the request is chosen, header
+// matchers take part in routing so handlers on the same path can be told apart
+func (r *Request) WithHeader(key string, val string) *Request {
+	return r.WithHeaderMatcher(key, MatchExact, val)
+}
+
+// WithHeaderMatcher - route to this request only when the incoming header satisfies the given MatchOp
+func (r *Request) WithHeaderMatcher(key string, op MatchOp, val string) *Request {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	r.HeaderMatchers = append(r.HeaderMatchers, NewValueMatcher(http.CanonicalHeaderKey(key), op, val))
+	return r
+}
+
+// WithBody - route to this request only when the incoming body is exactly body
+func (r *Request) WithBody(body string) *Request {
+	return r.addBodyMatcher(exactBodyMatcher(body))
+}
+
+// WithJSONBody - route to this request only when the incoming body is JSON semantically equal to v
+// (key order and whitespace are ignored; a string or []byte v is taken to be JSON text)
+func (r *Request) WithJSONBody(v interface{}) *Request {
+	return r.addBodyMatcher(jsonBodyMatcher(v))
+}
+
+// WithJSONPath - route to this request only when the value at expr (e.g. $.items[0].id) in the JSON body equals v
+func (r *Request) WithJSONPath(expr string, v interface{}) *Request {
+	return r.addBodyMatcher(jsonPathMatcher(expr, v))
+}
+
+// WithFormValue - route to this request only when the url-encoded or multipart form body has key set to val
+func (r *Request) WithFormValue(key string, val string) *Request {
+	return r.addBodyMatcher(formValueMatcher(key, val))
+}
+
+// WithBodyRegex - route to this request only when the incoming body matches the regular expression
+func (r *Request) WithBodyRegex(expr string) *Request {
+	return r.addBodyMatcher(regexBodyMatcher(expr))
+}
+
 // AddCookie - add a Cookie to a request object
 func (r *Request) AddCookie(c *http.Cookie) *Request {
+	r.lock().Lock()
+	defer r.lock().Unlock()
 	r.CookieArray = append(r.CookieArray, c)
 	return r
 }
 
 // Cookie - retrieve a cookie
 func (r *Request) Cookie(name string) (*http.Cookie, error) {
+	r.lock().RLock()
+	defer r.lock().RUnlock()
 	for _, thisCookie := range r.CookieArray {
 		if name == thisCookie.Name {
 			return thisCookie, nil
@@ -90,11 +218,15 @@ func (r *Request) Cookie(name string) (*http.Cookie, error) {
 
 // Cookies - retrieve all the cookies
 func (r *Request) Cookies() []*http.Cookie {
-	return r.CookieArray
+	r.lock().RLock()
+	defer r.lock().RUnlock()
+	return append([]*http.Cookie{}, r.CookieArray...)
 }
 
 // Handle - add a Responder to a Request
 func (r *Request) Handle(handle Responder) {
+	r.lock().Lock()
+	defer r.lock().Unlock()
 	r.CustomHandle = handle
 }
 
@@ -104,21 +236,254 @@ func (r *Request) Reply(status int) *Response {
 	return r.Response
 }
 
-// AddInjectionKey - key used to inject a value from the http.Request into the Body of Response
+// Calls - the incoming requests routed to this request handler so far
+func (r *Request) Calls() []*RecordedRequest {
+	return r.log().all()
+}
+
+// CallCount - the number of incoming requests routed to this request handler so far
+func (r *Request) CallCount() int {
+	return len(r.Calls())
+}
+
+// Times - expect exactly n calls, checked by HTTPFake.Verify
+func (r *Request) Times(n int) *Request {
+	return r.AtLeast(n).AtMost(n)
+}
+
+// AtLeast - expect n or more calls, checked by HTTPFake.Verify; combines with AtMost
+func (r *Request) AtLeast(n int) *Request {
+	r.log().expectAtLeast(n)
+	return r
+}
+
+// AtMost - expect no more than n calls, checked by HTTPFake.Verify; combines with AtLeast
+func (r *Request) AtMost(n int) *Request {
+	r.log().expectAtMost(n)
+	return r
+}
+
+// Never - expect no calls at all, checked by HTTPFake.Verify
+func (r *Request) Never() *Request {
+	return r.Times(0)
+}
+
+// AddInjectionKey - key used to inject a value from the http.Request into the Body of Response: path, method, host,
+// body, query:<name>, header:<name>, cookie:<name>, param:<name> or any key added with RegisterInjector
+// (ignored for templated bodies, see Response.Template)
 func (r *Request) AddInjectionKey(key string) *Request {
+	r.lock().Lock()
+	defer r.lock().Unlock()
 	r.InjectionKeys = append(r.InjectionKeys, key)
 	return r
 }
 
 // AddServiceEndpoint - uri used for service calls
 func (r *Request) AddServiceEndpoint(uri string) *Request {
+	r.lock().Lock()
+	defer r.lock().Unlock()
 	r.ServiceEndpoints = append(r.ServiceEndpoints, uri)
 	return r
 }
 
+// String - describe the request handler, e.g. for diagnostics
+func (r *Request) String() string {
+	r.lock().RLock()
+	defer r.lock().RUnlock()
+	s := r.Method + " " + r.URL.Path
+	if r.PathRegex != nil {
+		s = r.Method + " regexp:" + r.PathRegex.String()
+	}
+	for _, m := range r.QueryMatchers {
+		s += " [query " + m.String() + "]"
+	}
+	for _, m := range r.HeaderMatchers {
+		s += " [header " + m.String() + "]"
+	}
+	for _, m := range r.BodyMatchers {
+		s += " [" + m.String() + "]"
+	}
+	s += r.scenarioString()
+	if r.PriorityLevel != 0 {
+		s += fmt.Sprintf(" (priority %d)", r.PriorityLevel)
+	}
+	return s
+}
+
+// log - the call log of the request, created on first use when the Request was built as a literal
+func (r *Request) log() *callLog {
+	lazyLocks.Lock()
+	defer lazyLocks.Unlock()
+	if r.calls == nil {
+		r.calls = newCallLog()
+	}
+	return r.calls
+}
+
+func (r *Request) recordCall(rr *RecordedRequest) {
+	r.log().record(rr)
+}
+
+// unmetExpectation - a description of the unmet call count expectation, "" when satisfied
+func (r *Request) unmetExpectation() string {
+	return r.log().unmet()
+}
+
+// match returns a candidate when the incoming request fits this handler's method, path and conditions
+func (r *Request) match(req *http.Request, body []byte) (*candidate, bool) {
+	r.lock().RLock()
+	defer r.lock().RUnlock()
+	if r.Method != req.Method {
+		return nil, false
+	}
+
+	incoming := req.URL.String()
+	path := getURLPath(incoming)
+	if !strings.HasSuffix(path, "/") {
+		path = path + "/"
+	}
+	rhURL, _ := url.QueryUnescape(r.URL.String())
+
+	c := &candidate{handler: r, params: map[string]string{}, priority: r.PriorityLevel, conditions: r.conditions()}
+	switch {
+	case r.PathRegex != nil:
+		params, ok := r.matchRegex(req.URL.Path)
+		if !ok {
+			return nil, false
+		}
+		c.params = params
+		c.kind = regexPath
+	case r.pathTemplate != nil:
+		params, ok := r.pathTemplate.match(req.URL.Path)
+		if !ok {
+			return nil, false
+		}
+		c.params = params
+		c.kind = templatePath
+	case strings.HasPrefix(rhURL, "*"):
+		c.kind = wildcardPath
+	case rhURL == incoming:
+		c.kind = exactURL
+	case getURLPath(rhURL) == path && len(r.QueryMatchers) > 0:
+		c.kind = queryPath
+	case getURLPath(rhURL) == path:
+		c.kind = literalPath
+	default:
+		return nil, false
+	}
+
+	if !r.matchesQuery(req.URL.Query()) || !r.matchesHeaders(req.Header) || !r.matchesBody(body, req.Header) {
+		return nil, false
+	}
+	return c, true
+}
+
+func (r *Request) matchesQuery(query url.Values) bool {
+	for _, m := range r.QueryMatchers {
+		if !m.matches(query[m.Key]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Request) matchRegex(path string) (map[string]string, bool) {
+	re := r.pathRegex
+	if re == nil || re.String() != anchoredExpr(r.PathRegex) {
+		// PathRegex was assigned directly rather than through Match
+		re = anchorRegex(r.PathRegex)
+	}
+	m := re.FindStringSubmatch(path)
+	if m == nil {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			params[name] = m[i]
+		}
+	}
+	return params, true
+}
+
+// anchorRegex - re made to match whole paths only
+func anchorRegex(re *regexp.Regexp) *regexp.Regexp {
+	return regexp.MustCompile(anchoredExpr(re))
+}
+
+func anchoredExpr(re *regexp.Regexp) string {
+	return "^(?:" + re.String() + ")$"
+}
+
+func (r *Request) matchesHeaders(header http.Header) bool {
+	for _, m := range r.HeaderMatchers {
+		if !m.matches(header[m.Key]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Request) matchesBody(body []byte, header http.Header) bool {
+	for _, m := range r.BodyMatchers {
+		if !m.match(body, header) {
+			return false
+		}
+	}
+	return true
+}
+
+// conditions - the number of routing conditions beyond method and path
+func (r *Request) conditions() int {
+	n := len(r.QueryMatchers) + len(r.HeaderMatchers) + len(r.BodyMatchers)
+	if r.RequiredState != "" {
+		n++
+	}
+	return n
+}
+
+// responder - the Responder answering for this request
+func (r *Request) responder() Responder {
+	r.lock().RLock()
+	defer r.lock().RUnlock()
+	if r.CustomHandle != nil {
+		return r.CustomHandle
+	}
+	return DefaultResponder
+}
+
+// snapshot - a copy of the request, answering with a copy of response, safe to read while the original is being modified
+func (r *Request) snapshot(response *Response) *Request {
+	r.lock().RLock()
+	defer r.lock().RUnlock()
+	c := *r
+	c.Header = r.Header.Clone()
+	c.CookieArray = append([]*http.Cookie{}, r.CookieArray...)
+	c.InjectionKeys = append([]string{}, r.InjectionKeys...)
+	c.ServiceEndpoints = append([]string{}, r.ServiceEndpoints...)
+	c.Sequence = append([]*Response{}, r.Sequence...)
+	c.Response = response.snapshot()
+	return &c
+}
+
+func (r *Request) addBodyMatcher(m *BodyMatcher) *Request {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	r.BodyMatchers = append(r.BodyMatchers, m)
+	return r
+}
+
 func (r *Request) method(method, path string) *Request {
+	r.lock().Lock()
+	defer r.lock().Unlock()
 	r.URL.Path = normalize(path)
 	r.Method = strings.ToUpper(method)
+	r.pathTemplate = nil
+	r.PathRegex = nil
+	r.pathRegex = nil
+	if isPathTemplate(path) {
+		r.pathTemplate = newPathTemplate(path)
+	}
 	return r
 }
 
@@ -64,6 +64,47 @@ var _ = Describe("Request Tests", func() {
 		Ω(r.URL.Path).Should(Equal("path/to/head/"))
 	})
 
+	It("should keep a path template as the URL path", func() {
+		r.Get("/users/{id}")
+		Ω(r.URL.Path).Should(Equal("/users/{id}/"))
+	})
+
+	It("should mutate into a GET request matching a regular expression", func() {
+		r.GetRegex(`/v[0-9]+/items/.*`)
+		Ω(r.Method).Should(Equal("GET"))
+		Ω(r.PathRegex.String()).Should(Equal(`/v[0-9]+/items/.*`))
+	})
+
+	It("should drop the regular expression when a literal path is set", func() {
+		r.GetRegex(`/v[0-9]+/items/.*`).Get("/items")
+		Ω(r.PathRegex).Should(BeNil())
+		Ω(r.URL.Path).Should(Equal("/items/"))
+	})
+
+	It("should add a header matcher with a canonical key", func() {
+		r.WithHeader("x-api-version", "2")
+		Ω(r.HeaderMatchers).Should(HaveLen(1))
+		Ω(r.HeaderMatchers[0].Key).Should(Equal("X-Api-Version"))
+		Ω(r.Header).Should(BeEmpty())
+	})
+
+	It("should add a header matcher with an explicit operator", func() {
+		r.WithHeaderMatcher("Accept", MatchContains, "json")
+		Ω(r.HeaderMatchers[0].String()).Should(Equal("Accept contains json"))
+	})
+
+	It("should set an explicit priority", func() {
+		r.Priority(5)
+		Ω(r.PriorityLevel).Should(Equal(5))
+	})
+
+	It("should describe itself", func() {
+		r.Get("/search").WithQuery("q", "a").Priority(2)
+		Ω(r.String()).Should(Equal("GET /search/ [query q=a] (priority 2)"))
+		r.GetRegex(`/v[0-9]+/.*`)
+		Ω(r.String()).Should(Equal("GET regexp:/v[0-9]+/.* [query q=a] (priority 2)"))
+	})
+
 	It("should set a custom Responder onto the CustomHandler", func() {
 		Ω(r.CustomHandle).Should(BeNil())
 		r.Handle(DefaultResponder)
@@ -85,6 +126,20 @@ var _ = Describe("Request Tests", func() {
 		Ω(r.Header.Get("key")).Should(Equal("value"))
 	})
 
+	It("should add a query matcher", func() {
+		r.WithQuery("q", "a")
+		Ω(r.QueryMatchers).Should(HaveLen(1))
+		Ω(r.QueryMatchers[0].Key).Should(Equal("q"))
+		Ω(r.QueryMatchers[0].Op).Should(Equal(MatchExact))
+		Ω(r.QueryMatchers[0].Value).Should(Equal("a"))
+	})
+
+	It("should add a query matcher with an explicit operator", func() {
+		r.WithQueryMatcher("q", MatchRegex, "^a+$")
+		Ω(r.QueryMatchers[0].Op).Should(Equal(MatchRegex))
+		Ω(r.QueryMatchers[0].String()).Should(Equal("q~/^a+$/"))
+	})
+
 	It("should add and retrieve a cookie", func() {
 		cookie := &http.Cookie{Name: "unknownShopperId", Value: "123"}
 		r.AddCookie(cookie)
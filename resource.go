@@ -0,0 +1,266 @@
+package fakehttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Resource - an in-memory REST collection of JSON objects served by the fake server
+//
+// Its items are identified by their IDField ("id" unless changed); POSTed items without one get the next
+// free integer
+type Resource struct {
+	Path     string
+	IDField  string
+	Handlers []*Request
+	server   *HTTPFake
+	items    map[string]map[string]interface{}
+	order    []string
+	mu       *sync.RWMutex
+}
+
+// Resource register the handlers of an in-memory CRUD resource under path:
+//
+//	GET path          200 with the list of items
+//	GET path/{id}     200 with the item, 404 when missing
+//	POST path         201 with the created item and its Location, 409 when its id is taken
+//	PUT path/{id}     200 with the replaced item, 404 when missing
+//	PATCH path/{id}   200 with the item updated by the fields sent, 404 when missing
+//	DELETE path/{id}  204, 404 when missing
+//
+// Bodies that are not JSON objects are answered 400
+func (f *HTTPFake) Resource(path string) *Resource {
+	path = "/" + strings.Trim(path, "/")
+	res := &Resource{
+		Path:    path,
+		IDField: "id",
+		server:  f,
+		items:   map[string]map[string]interface{}{},
+		mu:      &sync.RWMutex{},
+	}
+	item := path + "/{id}"
+	res.handle(f.NewHandler(false).Get(path), res.list)
+	res.handle(f.NewHandler(false).Get(item), res.get)
+	res.handle(f.NewHandler(false).Post(path), res.create)
+	res.handle(f.NewHandler(false).Put(item), res.replace)
+	res.handle(f.NewHandler(false).Patch(item), res.update)
+	res.handle(f.NewHandler(false).Delete(item), res.remove)
+	return res
+}
+
+func (res *Resource) handle(rh *Request, handle func(r *http.Request) (int, interface{})) {
+	rh.Handle(func(w http.ResponseWriter, r *http.Request, rh *Request) {
+		status, body := handle(r)
+		if loc, ok := body.(location); ok {
+			w.Header().Set("Location", res.Path+"/"+loc.id)
+			body = loc.item
+		}
+		if body == nil {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	})
+	res.Handlers = append(res.Handlers, rh)
+}
+
+// location - a created item along with the id its Location header points to
+type location struct {
+	id   string
+	item map[string]interface{}
+}
+
+// Seed - add items, Go values marshalling to JSON objects or JSON text; panics on anything else
+func (res *Resource) Seed(items ...interface{}) *Resource {
+	for _, v := range items {
+		item, ok := normalizeJSON(v).(map[string]interface{})
+		if !ok {
+			panic(fmt.Sprintf("fakehttp: cannot seed %s with %v: not a JSON object", res.Path, v))
+		}
+		res.store(item)
+	}
+	return res
+}
+
+// SeedFile - add the items of a JSON file holding an array of objects, resolved against the FixtureDir of the
+// server when relative; panics when the file cannot be read
+func (res *Resource) SeedFile(path string) *Resource {
+	if dir := res.server.fixtures(); dir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		panic(fmt.Sprintf("fakehttp: cannot seed %s: %v", res.Path, err))
+	}
+	var items []interface{}
+	if err := json.Unmarshal(b, &items); err != nil {
+		panic(fmt.Sprintf("fakehttp: cannot seed %s from %s: %v", res.Path, path, err))
+	}
+	return res.Seed(items...)
+}
+
+// Items - a copy of the items of the resource, in creation order
+func (res *Resource) Items() []map[string]interface{} {
+	res.mu.RLock()
+	defer res.mu.RUnlock()
+	items := []map[string]interface{}{}
+	for _, id := range res.order {
+		items = append(items, copyItem(res.items[id]))
+	}
+	return items
+}
+
+// Item - a copy of the item with the given id
+func (res *Resource) Item(id string) (map[string]interface{}, bool) {
+	res.mu.RLock()
+	defer res.mu.RUnlock()
+	item, ok := res.items[id]
+	return copyItem(item), ok
+}
+
+// Clear - remove every item
+func (res *Resource) Clear() *Resource {
+	res.mu.Lock()
+	defer res.mu.Unlock()
+	res.items = map[string]map[string]interface{}{}
+	res.order = nil
+	return res
+}
+
+func (res *Resource) list(r *http.Request) (int, interface{}) {
+	return http.StatusOK, res.Items()
+}
+
+func (res *Resource) get(r *http.Request) (int, interface{}) {
+	item, ok := res.Item(Call(r).Params["id"])
+	if !ok {
+		return http.StatusNotFound, nil
+	}
+	return http.StatusOK, item
+}
+
+func (res *Resource) create(r *http.Request) (int, interface{}) {
+	item, ok := decodeItem(Call(r).Body)
+	if !ok {
+		return http.StatusBadRequest, nil
+	}
+	id, ok := res.store(item)
+	if !ok {
+		return http.StatusConflict, nil
+	}
+	return http.StatusCreated, location{id: id, item: copyItem(item)}
+}
+
+func (res *Resource) replace(r *http.Request) (int, interface{}) {
+	return res.modify(r, func(existing map[string]interface{}, sent map[string]interface{}) map[string]interface{} {
+		return sent
+	})
+}
+
+func (res *Resource) update(r *http.Request) (int, interface{}) {
+	return res.modify(r, func(existing map[string]interface{}, sent map[string]interface{}) map[string]interface{} {
+		for k, v := range sent {
+			existing[k] = v
+		}
+		return existing
+	})
+}
+
+// modify swaps the item addressed by r for what change makes of it and the object sent, keeping its id
+func (res *Resource) modify(r *http.Request, change func(existing, sent map[string]interface{}) map[string]interface{}) (int, interface{}) {
+	call := Call(r)
+	sent, ok := decodeItem(call.Body)
+	if !ok {
+		return http.StatusBadRequest, nil
+	}
+	id := call.Params["id"]
+	res.mu.Lock()
+	defer res.mu.Unlock()
+	existing, ok := res.items[id]
+	if !ok {
+		return http.StatusNotFound, nil
+	}
+	item := change(existing, sent)
+	item[res.IDField] = existing[res.IDField]
+	res.items[id] = item
+	return http.StatusOK, copyItem(item)
+}
+
+func (res *Resource) remove(r *http.Request) (int, interface{}) {
+	id := Call(r).Params["id"]
+	res.mu.Lock()
+	defer res.mu.Unlock()
+	if _, ok := res.items[id]; !ok {
+		return http.StatusNotFound, nil
+	}
+	delete(res.items, id)
+	for i, o := range res.order {
+		if o == id {
+			res.order = append(res.order[:i], res.order[i+1:]...)
+			break
+		}
+	}
+	return http.StatusNoContent, nil
+}
+
+// store adds item, giving it the next free integer id when it has none; false when its id is taken
+func (res *Resource) store(item map[string]interface{}) (string, bool) {
+	res.mu.Lock()
+	defer res.mu.Unlock()
+	if _, ok := item[res.IDField]; !ok {
+		item[res.IDField] = float64(res.nextID())
+	}
+	id := itemID(item[res.IDField])
+	if _, ok := res.items[id]; ok {
+		return id, false
+	}
+	res.items[id] = item
+	res.order = append(res.order, id)
+	return id, true
+}
+
+// nextID - one more than the highest integer id in use
+func (res *Resource) nextID() int {
+	max := 0
+	for id := range res.items {
+		if n, err := strconv.Atoi(id); err == nil && n > max {
+			max = n
+		}
+	}
+	return max + 1
+}
+
+// itemID - the id an item is stored under, numbers written in full rather than in exponent form
+func itemID(v interface{}) string {
+	if n, ok := v.(float64); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+func decodeItem(body []byte) (map[string]interface{}, bool) {
+	var item map[string]interface{}
+	if err := json.Unmarshal(body, &item); err != nil || item == nil {
+		return nil, false
+	}
+	return item, true
+}
+
+func copyItem(item map[string]interface{}) map[string]interface{} {
+	if item == nil {
+		return nil
+	}
+	c := map[string]interface{}{}
+	for k, v := range item {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,144 @@
+package fakehttp_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+var _ = Describe("Resource Tests", func() {
+	var server *HTTPFake
+	var users *Resource
+
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	BeforeEach(func() {
+		server = Server().StartAny()
+		users = server.Resource("/users").Seed(user{ID: 1, Name: "Ann"}, `{"id": 2, "name": "Bob"}`)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	send := func(method string, path string, body string) (*http.Response, string) {
+		req, err := http.NewRequest(method, server.ResolveURL(path), strings.NewReader(body))
+		Ω(err).ShouldNot(HaveOccurred())
+		res, err := http.DefaultClient.Do(req)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		b, _ := ioutil.ReadAll(res.Body)
+		return res, string(b)
+	}
+
+	It("should list the items", func() {
+		res, body := send("GET", "/users", "")
+		Ω(res.StatusCode).Should(Equal(200))
+		Ω(res.Header.Get("Content-Type")).Should(Equal("application/json"))
+		Ω(body).Should(MatchJSON(`[{"id": 1, "name": "Ann"}, {"id": 2, "name": "Bob"}]`))
+	})
+
+	It("should get an item by id", func() {
+		res, body := send("GET", "/users/2", "")
+		Ω(res.StatusCode).Should(Equal(200))
+		Ω(body).Should(MatchJSON(`{"id": 2, "name": "Bob"}`))
+
+		res, _ = send("GET", "/users/9", "")
+		Ω(res.StatusCode).Should(Equal(404))
+	})
+
+	It("should create an item with the next id", func() {
+		res, body := send("POST", "/users", `{"name": "Cid"}`)
+		Ω(res.StatusCode).Should(Equal(201))
+		Ω(res.Header.Get("Location")).Should(Equal("/users/3"))
+		Ω(body).Should(MatchJSON(`{"id": 3, "name": "Cid"}`))
+		Ω(users.Items()).Should(HaveLen(3))
+	})
+
+	It("should address items with large numeric ids", func() {
+		users.Seed(`{"id": 1234567, "name": "Big"}`)
+
+		res, body := send("GET", "/users/1234567", "")
+		Ω(res.StatusCode).Should(Equal(200))
+		Ω(body).Should(MatchJSON(`{"id": 1234567, "name": "Big"}`))
+
+		res, _ = send("POST", "/users", `{"name": "Next"}`)
+		Ω(res.Header.Get("Location")).Should(Equal("/users/1234568"))
+		res, _ = send("GET", "/users/1234568", "")
+		Ω(res.StatusCode).Should(Equal(200))
+	})
+
+	It("should refuse to create an item whose id is taken", func() {
+		res, _ := send("POST", "/users", `{"id": 1, "name": "Dup"}`)
+		Ω(res.StatusCode).Should(Equal(409))
+	})
+
+	It("should answer 400 when the body is not a JSON object", func() {
+		res, _ := send("POST", "/users", `not json`)
+		Ω(res.StatusCode).Should(Equal(400))
+	})
+
+	It("should replace an item, keeping its id", func() {
+		res, body := send("PUT", "/users/1", `{"id": 5, "email": "ann@example.com"}`)
+		Ω(res.StatusCode).Should(Equal(200))
+		Ω(body).Should(MatchJSON(`{"id": 1, "email": "ann@example.com"}`))
+
+		res, _ = send("PUT", "/users/9", `{}`)
+		Ω(res.StatusCode).Should(Equal(404))
+	})
+
+	It("should update the fields sent", func() {
+		res, body := send("PATCH", "/users/1", `{"email": "ann@example.com"}`)
+		Ω(res.StatusCode).Should(Equal(200))
+		Ω(body).Should(MatchJSON(`{"id": 1, "name": "Ann", "email": "ann@example.com"}`))
+
+		item, ok := users.Item("1")
+		Ω(ok).Should(BeTrue())
+		Ω(item["email"]).Should(Equal("ann@example.com"))
+	})
+
+	It("should delete an item", func() {
+		res, _ := send("DELETE", "/users/1", "")
+		Ω(res.StatusCode).Should(Equal(204))
+
+		res, _ = send("DELETE", "/users/1", "")
+		Ω(res.StatusCode).Should(Equal(404))
+
+		_, body := send("GET", "/users", "")
+		Ω(body).Should(MatchJSON(`[{"id": 2, "name": "Bob"}]`))
+	})
+
+	It("should seed from a file in the fixture directory", func() {
+		dir, err := ioutil.TempDir("", "fakehttp-resource")
+		Ω(err).ShouldNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		Ω(ioutil.WriteFile(filepath.Join(dir, "orders.json"), []byte(`[{"id": "a1", "total": 10}]`), 0644)).Should(Succeed())
+
+		server.FixtureDir(dir)
+		server.Resource("/orders").SeedFile("orders.json")
+
+		res, body := send("GET", "/orders/a1", "")
+		Ω(res.StatusCode).Should(Equal(200))
+		Ω(body).Should(MatchJSON(`{"id": "a1", "total": 10}`))
+	})
+
+	It("should panic when seeded with something else than an object", func() {
+		Ω(func() { users.Seed([]int{1}) }).Should(PanicWith(ContainSubstring("not a JSON object")))
+	})
+
+	It("should forget every item when cleared", func() {
+		users.Clear()
+		_, body := send("GET", "/users", "")
+		Ω(body).Should(MatchJSON(`[]`))
+	})
+})
@@ -16,23 +16,50 @@ type Responder func(w http.ResponseWriter, r *http.Request, rh *Request)
 
 // DefaultResponder - the default (simple) responder
 func DefaultResponder(w http.ResponseWriter, r *http.Request, rh *Request) {
-	if (len(rh.Response.Header)) > 0 {
-		for k := range rh.Response.Header {
-			w.Header().Add(k, rh.Response.Header.Get(k))
-		}
+	response := callResponse(r, rh).snapshot()
+	if !wait(r, response.delay()) {
+		return
+	}
+	body, done, err := response.openBody(Call(r).FixtureDir)
+	if err == nil {
+		defer done()
+		body, err = response.render(body, r)
 	}
-	if rh.Response.StatusCode > 0 {
-		w.WriteHeader(rh.Response.StatusCode)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("--- 500 " + err.Error()))
+		return
 	}
-	if (len(rh.Response.BodyBuffer)) > 0 {
-		w.Write(rh.Response.BodyBuffer)
+	if (len(response.Header)) > 0 {
+		for k := range response.Header {
+			w.Header().Add(k, response.Header.Get(k))
+		}
 	}
+	if response.StatusCode > 0 {
+		w.WriteHeader(response.StatusCode)
+	}
+	writeBody(w, r, body, response.BytesPerSecond)
 }
 
 // SophisticatedResponder - responder with validation of headers and cookies, body insertion of data from request and invocation of service dependencies
 func SophisticatedResponder(w http.ResponseWriter, httpRequest *http.Request, fakeRequest *Request) {
+	fakeRequest = fakeRequest.snapshot(callResponse(httpRequest, fakeRequest))
+	if !wait(httpRequest, fakeRequest.Response.delay()) {
+		return
+	}
 	statusCode := fakeRequest.Response.StatusCode
-	body := fakeRequest.Response.BodyBuffer
+	body, err := fakeRequest.Response.readBody(Call(httpRequest).FixtureDir)
+	if err == nil && fakeRequest.Response.Templated {
+		body, err = renderTemplate(body, httpRequest)
+	} else if err == nil && len(body) > 0 && len(fakeRequest.InjectionKeys) > 0 {
+		var injected string
+		injected, err = inject(string(body), fakeRequest.InjectionKeys, httpRequest)
+		body = []byte(injected)
+	}
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		body = []byte("--- 500 " + err.Error())
+	}
 	responseHeader := fakeRequest.Response.Header
 
 	if len(fakeRequest.Header) > 0 {
@@ -71,15 +98,6 @@ func SophisticatedResponder(w http.ResponseWriter, httpRequest *http.Request, fa
 	}
 	if (len(body)) > 0 {
 		b := string(body)
-		if len(fakeRequest.InjectionKeys) > 0 {
-			for _, k := range fakeRequest.InjectionKeys {
-				if k == "path" {
-					body = []byte(fmt.Sprintf(b, strings.TrimPrefix(httpRequest.URL.Path, "/")))
-					b = string(body)
-				}
-			}
-		}
-
 		if len(serviceResponses) > 0 {
 			b += serviceResponses
 		}
@@ -88,7 +106,7 @@ func SophisticatedResponder(w http.ResponseWriter, httpRequest *http.Request, fa
 			b = "<html><head><title>fakeserver</title></head><body>" + b + "</body></html>"
 		}
 
-		w.Write([]byte(b))
+		writeBody(w, httpRequest, strings.NewReader(b), fakeRequest.Response.BytesPerSecond)
 	}
 }
 
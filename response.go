@@ -1,35 +1,132 @@
 package fakehttp
 
-import "net/http"
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
 
 type Response struct {
-	StatusCode int
-	Header     http.Header
-	BodyBuffer []byte
+	StatusCode     int
+	Header         http.Header
+	BodyBuffer     []byte
+	BodyPath       string
+	Templated      bool
+	DelayMin       time.Duration
+	DelayMax       time.Duration
+	BytesPerSecond int
+	Fault          Fault
+	TruncateAt     int
+	owner          *Request
+	rnd            *rand.Rand
+	bodyReader     io.Reader
+	streamMu       *sync.Mutex
+	mu             *sync.RWMutex
 }
 
 func NewResponse() *Response {
 	return &Response{
 		Header: make(http.Header),
+		mu:     &sync.RWMutex{},
 	}
 }
 
+// lazyLocks - guards the creation of the lock of a Request or Response built as a literal rather than by its constructor
+var lazyLocks = &sync.Mutex{}
+
+// lock - the lock of the response, created on first use when the Response was built as a literal
+func (r *Response) lock() *sync.RWMutex {
+	lazyLocks.Lock()
+	defer lazyLocks.Unlock()
+	if r.mu == nil {
+		r.mu = &sync.RWMutex{}
+	}
+	return r.mu
+}
+
 func (r *Response) Status(status int) *Response {
+	r.lock().Lock()
+	defer r.lock().Unlock()
 	r.StatusCode = status
 	return r
 }
 
 func (r *Response) SetHeader(key string, value string) *Response {
+	r.lock().Lock()
+	defer r.lock().Unlock()
 	r.Header.Set(key, value)
 	return r
 }
 
 func (r *Response) AddHeader(key string, value string) *Response {
+	r.lock().Lock()
+	defer r.lock().Unlock()
 	r.Header.Add(key, value)
 	return r
 }
 
 func (r *Response) BodyString(body string) *Response {
+	r.lock().Lock()
+	defer r.lock().Unlock()
 	r.BodyBuffer = []byte(body)
+	r.BodyPath = ""
+	r.bodyReader = nil
+	return r
+}
+
+// JSON - answer with v marshalled to JSON, as application/json; panics when v cannot be marshalled
+func (r *Response) JSON(v interface{}) *Response {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("fakehttp: cannot marshal response JSON: %v", err))
+	}
+	return r.body(b, "application/json")
+}
+
+// PrettyJSON - like JSON, indented for readability
+func (r *Response) PrettyJSON(v interface{}) *Response {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		panic(fmt.Sprintf("fakehttp: cannot marshal response JSON: %v", err))
+	}
+	return r.body(b, "application/json")
+}
+
+// XML - answer with v marshalled to XML, as application/xml; panics when v cannot be marshalled
+func (r *Response) XML(v interface{}) *Response {
+	b, err := xml.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("fakehttp: cannot marshal response XML: %v", err))
+	}
+	return r.body(append([]byte(xml.Header), b...), "application/xml")
+}
+
+// Text - answer with s, as text/plain
+func (r *Response) Text(s string) *Response {
+	return r.body([]byte(s), "text/plain; charset=utf-8")
+}
+
+func (r *Response) body(b []byte, contentType string) *Response {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	r.BodyBuffer = b
+	r.BodyPath = ""
+	r.bodyReader = nil
+	r.Header.Set("Content-Type", contentType)
 	return r
 }
+
+// snapshot - a copy of the response safe to read while the original is being modified
+func (r *Response) snapshot() *Response {
+	r.lock().RLock()
+	defer r.lock().RUnlock()
+	c := *r
+	c.Header = r.Header.Clone()
+	c.BodyBuffer = append([]byte{}, r.BodyBuffer...)
+	return &c
+}
@@ -67,4 +67,42 @@ var _ = Describe("Response Tests", func() {
 		r.BodyString("this is a body string")
 		Ω(string(r.BodyBuffer)).Should(Equal("this is a body string"))
 	})
+
+	It("should marshal a JSON body and set its Content-Type", func() {
+		r.JSON(map[string]interface{}{"id": 1, "name": "Ann"})
+		Ω(string(r.BodyBuffer)).Should(MatchJSON(`{"id": 1, "name": "Ann"}`))
+		Ω(string(r.BodyBuffer)).ShouldNot(ContainSubstring("\n"))
+		Ω(r.Header.Get("Content-Type")).Should(Equal("application/json"))
+	})
+
+	It("should pretty print a JSON body", func() {
+		r.PrettyJSON(map[string]int{"id": 1})
+		Ω(string(r.BodyBuffer)).Should(Equal("{\n  \"id\": 1\n}"))
+		Ω(r.Header.Get("Content-Type")).Should(Equal("application/json"))
+	})
+
+	It("should panic when the JSON body cannot be marshalled", func() {
+		Ω(func() { r.JSON(make(chan int)) }).Should(PanicWith(ContainSubstring("cannot marshal response JSON")))
+	})
+
+	It("should marshal an XML body and set its Content-Type", func() {
+		type user struct {
+			XMLName struct{} `xml:"user"`
+			Name    string   `xml:"name"`
+		}
+		r.XML(user{Name: "Ann"})
+		Ω(string(r.BodyBuffer)).Should(HaveSuffix("<user><name>Ann</name></user>"))
+		Ω(string(r.BodyBuffer)).Should(HavePrefix("<?xml"))
+		Ω(r.Header.Get("Content-Type")).Should(Equal("application/xml"))
+	})
+
+	It("should panic when the XML body cannot be marshalled", func() {
+		Ω(func() { r.XML(make(chan int)) }).Should(PanicWith(ContainSubstring("cannot marshal response XML")))
+	})
+
+	It("should set a text body and its Content-Type", func() {
+		r.Text("hello")
+		Ω(string(r.BodyBuffer)).Should(Equal("hello"))
+		Ω(r.Header.Get("Content-Type")).Should(Equal("text/plain; charset=utf-8"))
+	})
 })
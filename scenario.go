@@ -0,0 +1,118 @@
+package fakehttp
+
+// ScenarioStarted - the state every scenario is in until a Request transitions it
+const ScenarioStarted = "Started"
+
+// DefaultScenario - the scenario of Requests using InState or TransitionTo without naming one
+const DefaultScenario = "default"
+
+// Scenario - make the scenario name the one InState and TransitionTo refer to
+func (r *Request) Scenario(name string) *Request {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	r.ScenarioName = name
+	return r
+}
+
+// InState - only match while the scenario of the request is in state
+func (r *Request) InState(state string) *Request {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	r.RequiredState = state
+	return r
+}
+
+// TransitionTo - move the scenario of the request to state every time the request is matched
+func (r *Request) TransitionTo(state string) *Request {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	r.NewState = state
+	return r
+}
+
+// scenario - the scenario, required state and next state of the request
+func (r *Request) scenario() (name string, required string, next string) {
+	r.lock().RLock()
+	defer r.lock().RUnlock()
+	name = r.ScenarioName
+	if name == "" {
+		name = DefaultScenario
+	}
+	return name, r.RequiredState, r.NewState
+}
+
+func (r *Request) scenarioString() string {
+	if r.RequiredState == "" && r.NewState == "" {
+		return ""
+	}
+	name := r.ScenarioName
+	if name == "" {
+		name = DefaultScenario
+	}
+	s := " [scenario " + name
+	if r.RequiredState != "" {
+		s += " in " + r.RequiredState
+	}
+	if r.NewState != "" {
+		s += " -> " + r.NewState
+	}
+	return s + "]"
+}
+
+// ScenarioState - the current state of the named scenario
+func (f *HTTPFake) ScenarioState(name string) string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if state, ok := f.scenarios[name]; ok {
+		return state
+	}
+	return ScenarioStarted
+}
+
+// ScenarioStates - the current state of every scenario that has left ScenarioStarted
+func (f *HTTPFake) ScenarioStates() map[string]string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	states := map[string]string{}
+	for name, state := range f.scenarios {
+		states[name] = state
+	}
+	return states
+}
+
+// ResetScenarios - put every scenario back in ScenarioStarted
+func (f *HTTPFake) ResetScenarios() *HTTPFake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.scenarios = map[string]string{}
+	return f
+}
+
+// inScenarioState tells whether the scenario of rh is in the state rh requires
+func (f *HTTPFake) inScenarioState(rh *Request) bool {
+	name, required, _ := rh.scenario()
+	return required == "" || f.ScenarioState(name) == required
+}
+
+// transition moves the scenario of a matched rh to its next state, checking again, atomically, that it is still
+// in the state rh requires; false when a concurrent request changed the state since rh was matched, in which
+// case the request must be routed again
+func (f *HTTPFake) transition(rh *Request) bool {
+	name, required, next := rh.scenario()
+	if required == "" && next == "" {
+		return true
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	current, ok := f.scenarios[name]
+	if !ok {
+		current = ScenarioStarted
+	}
+	if required != "" && current != required {
+		return false
+	}
+	if next != "" {
+		f.scenarios[name] = next
+	}
+	return true
+}
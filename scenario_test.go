@@ -0,0 +1,138 @@
+package fakehttp_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+var _ = Describe("Scenario Tests", func() {
+	var server *HTTPFake
+
+	BeforeEach(func() {
+		server = Server().StartAny()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	send := func(method string, path string) (int, string) {
+		req, err := http.NewRequest(method, server.ResolveURL(path), strings.NewReader(""))
+		Ω(err).ShouldNot(HaveOccurred())
+		res, err := http.DefaultClient.Do(req)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		return res.StatusCode, string(body)
+	}
+
+	It("should change what later requests return once a request transitions the scenario", func() {
+		server.NewHandler(false).Get("/orders/1").Reply(200).BodyString("created")
+		server.NewHandler(false).Post("/orders/1/pay").Scenario("order").TransitionTo("paid").Reply(204)
+		server.NewHandler(false).Get("/orders/1").Scenario("order").InState("paid").Reply(200).BodyString("paid")
+
+		_, body := send("GET", "/orders/1")
+		Ω(body).Should(Equal("created"))
+		Ω(server.ScenarioState("order")).Should(Equal(ScenarioStarted))
+
+		status, _ := send("POST", "/orders/1/pay")
+		Ω(status).Should(Equal(204))
+		Ω(server.ScenarioState("order")).Should(Equal("paid"))
+
+		_, body = send("GET", "/orders/1")
+		Ω(body).Should(Equal("paid"))
+	})
+
+	It("should not match a request gated on another state", func() {
+		server.NewHandler(false).Delete("/orders/1").Scenario("order").InState("paid").Reply(204)
+
+		status, _ := send("DELETE", "/orders/1")
+		Ω(status).Should(Equal(404))
+	})
+
+	It("should step through states", func() {
+		server.NewHandler(false).Get("/step").InState(ScenarioStarted).TransitionTo("one").Reply(200).BodyString("first")
+		server.NewHandler(false).Get("/step").InState("one").TransitionTo("two").Reply(200).BodyString("second")
+
+		_, first := send("GET", "/step")
+		_, second := send("GET", "/step")
+		status, _ := send("GET", "/step")
+		Ω(first).Should(Equal("first"))
+		Ω(second).Should(Equal("second"))
+		Ω(status).Should(Equal(404))
+		Ω(server.ScenarioStates()).Should(Equal(map[string]string{DefaultScenario: "two"}))
+	})
+
+	It("should reset the scenarios", func() {
+		server.NewHandler(false).Post("/pay").Scenario("order").TransitionTo("paid").Reply(204)
+		send("POST", "/pay")
+		Ω(server.ScenarioState("order")).Should(Equal("paid"))
+
+		server.ResetScenarios()
+		Ω(server.ScenarioState("order")).Should(Equal(ScenarioStarted))
+		Ω(server.ScenarioStates()).Should(BeEmpty())
+	})
+
+	It("should describe the scenario of a request", func() {
+		rh := NewRequest(false).Get("/orders").Scenario("order").InState("created").TransitionTo("paid")
+		Ω(rh.String()).Should(Equal("GET /orders/ [scenario order in created -> paid]"))
+	})
+
+	It("should let only one of concurrent requests take a transition", func() {
+		server.NewHandler(false).Post("/orders/1/pay").Scenario("order").InState(ScenarioStarted).TransitionTo("paid").Reply(204)
+
+		statuses := make(chan int, 20)
+		var wg sync.WaitGroup
+		for i := 0; i < cap(statuses); i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				status, _ := send("POST", "/orders/1/pay")
+				statuses <- status
+			}()
+		}
+		wg.Wait()
+		close(statuses)
+
+		counts := map[int]int{}
+		for status := range statuses {
+			counts[status]++
+		}
+		Ω(counts).Should(Equal(map[int]int{204: 1, 404: cap(statuses) - 1}))
+		Ω(server.UnmatchedRequests()).Should(HaveLen(cap(statuses) - 1))
+	})
+
+	It("should route requests that lose a transition race against the new state", func() {
+		server.NewHandler(false).Post("/pay").Scenario("order").InState(ScenarioStarted).TransitionTo("paid").Reply(204)
+		server.NewHandler(false).Post("/pay").Scenario("order").InState("paid").Reply(409)
+
+		statuses := make(chan int, 50)
+		var wg sync.WaitGroup
+		for i := 0; i < cap(statuses); i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				status, _ := send("POST", "/pay")
+				statuses <- status
+			}()
+		}
+		wg.Wait()
+		close(statuses)
+
+		counts := map[int]int{}
+		for status := range statuses {
+			counts[status]++
+		}
+		Ω(counts).Should(Equal(map[int]int{204: 1, 409: cap(statuses) - 1}))
+		Ω(server.UnmatchedRequests()).Should(BeEmpty())
+	})
+})
@@ -0,0 +1,96 @@
+package fakehttp
+
+import "net/http"
+
+// SequenceEnd - what a Request answers once its ReplyOnce sequence is used up
+type SequenceEnd int
+
+const (
+	// SequenceRepeatLast - keep answering with the last Response of the sequence
+	SequenceRepeatLast SequenceEnd = iota
+	// SequenceNotFound - answer 404
+	SequenceNotFound
+	// SequenceCycle - start the sequence over
+	SequenceCycle
+)
+
+// ReplyOnce - append a Response, with the given Status Code, used for a single call
+//
+// Successive calls are answered by the sequence in order (e.g. ReplyOnce(503).Then(503).Then(200));
+// WhenExhausted decides what happens after that. A sequence takes precedence over Reply
+func (r *Request) ReplyOnce(status int) *Response {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	return r.appendToSequence(status)
+}
+
+// WhenExhausted - set what the Request answers once its ReplyOnce sequence is used up (SequenceRepeatLast by default)
+func (r *Request) WhenExhausted(end SequenceEnd) *Request {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	r.SequenceEnd = end
+	return r
+}
+
+// Then - append another single-use Response, with the given Status Code, after this one
+//
+// Called on the Response returned by Reply, that Response becomes the first of the sequence
+func (r *Response) Then(status int) *Response {
+	owner := r.owner
+	if owner == nil {
+		panic("fakehttp: Then needs a Response obtained from Request.Reply or Request.ReplyOnce")
+	}
+	owner.lock().Lock()
+	defer owner.lock().Unlock()
+	if !owner.inSequence(r) {
+		owner.Sequence = append(owner.Sequence, r)
+	}
+	return owner.appendToSequence(status)
+}
+
+func (r *Request) appendToSequence(status int) *Response {
+	response := NewResponse().Status(status)
+	response.owner = r
+	r.Sequence = append(r.Sequence, response)
+	return response
+}
+
+func (r *Request) inSequence(response *Response) bool {
+	for _, s := range r.Sequence {
+		if s == response {
+			return true
+		}
+	}
+	return false
+}
+
+// nextResponse picks the Response answering the current call, false once a SequenceNotFound sequence is used up
+func (r *Request) nextResponse() (*Response, bool) {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	n := len(r.Sequence)
+	if n == 0 {
+		return r.Response, true
+	}
+	i := r.sequenceIndex
+	r.sequenceIndex++
+	if i < n {
+		return r.Sequence[i], true
+	}
+	switch r.SequenceEnd {
+	case SequenceNotFound:
+		return nil, false
+	case SequenceCycle:
+		return r.Sequence[i%n], true
+	default:
+		return r.Sequence[n-1], true
+	}
+}
+
+// callResponse - the Response chosen for the current call, falling back to rh.Response outside of HTTPFake
+func callResponse(r *http.Request, rh *Request) *Response {
+	if response := Call(r).Response; response != nil {
+		return response
+	}
+	return rh.Response
+}
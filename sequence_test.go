@@ -0,0 +1,92 @@
+package fakehttp_test
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+var _ = Describe("Response Sequence Tests", func() {
+	var server *HTTPFake
+
+	BeforeEach(func() {
+		server = Server().StartAny()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	statuses := func(n int) []int {
+		codes := []int{}
+		for i := 0; i < n; i++ {
+			res, err := http.Get(server.ResolveURL("/flaky"))
+			Ω(err).ShouldNot(HaveOccurred())
+			ioutil.ReadAll(res.Body)
+			res.Body.Close()
+			codes = append(codes, res.StatusCode)
+		}
+		return codes
+	}
+
+	It("should answer successive calls in order and then repeat the last response", func() {
+		server.NewHandler(false).Get("/flaky").ReplyOnce(503).Then(503).Then(200).BodyString("OK")
+
+		Ω(statuses(5)).Should(Equal([]int{503, 503, 200, 200, 200}))
+	})
+
+	It("should answer 404 once the sequence is used up", func() {
+		rh := server.NewHandler(false).Get("/flaky").WhenExhausted(SequenceNotFound)
+		rh.ReplyOnce(500)
+		rh.ReplyOnce(200)
+
+		Ω(statuses(3)).Should(Equal([]int{500, 200, 404}))
+	})
+
+	It("should start over once the sequence is used up", func() {
+		server.NewHandler(false).Get("/flaky").WhenExhausted(SequenceCycle).ReplyOnce(200).Then(429)
+
+		Ω(statuses(5)).Should(Equal([]int{200, 429, 200, 429, 200}))
+	})
+
+	It("should start a sequence from the Response returned by Reply", func() {
+		server.NewHandler(false).Get("/flaky").Reply(503).Then(200)
+
+		Ω(statuses(3)).Should(Equal([]int{503, 200, 200}))
+	})
+
+	It("should give each step its own headers and body", func() {
+		rh := server.NewHandler(false).Get("/flaky")
+		rh.ReplyOnce(429).SetHeader("Retry-After", "1").BodyString("slow down").Then(200).BodyString("done")
+
+		res, err := http.Get(server.ResolveURL("/flaky"))
+		Ω(err).ShouldNot(HaveOccurred())
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		Ω(res.Header.Get("Retry-After")).Should(Equal("1"))
+		Ω(string(body)).Should(Equal("slow down"))
+
+		res, err = http.Get(server.ResolveURL("/flaky"))
+		Ω(err).ShouldNot(HaveOccurred())
+		body, _ = ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		Ω(res.Header.Get("Retry-After")).Should(BeEmpty())
+		Ω(string(body)).Should(Equal("done"))
+	})
+
+	It("should walk the sequence with SophisticatedResponder", func() {
+		rh := server.NewHandler(false).Get("/flaky")
+		rh.Handle(SophisticatedResponder)
+		rh.ReplyOnce(503).Then(200)
+
+		Ω(statuses(2)).Should(Equal([]int{503, 200}))
+	})
+
+	It("should refuse Then on a standalone Response", func() {
+		Ω(func() { NewResponse().Then(200) }).Should(Panic())
+	})
+})
@@ -1,53 +1,113 @@
 package fakehttp
 
 import (
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
-	netURL "net/url"
 	"strings"
+	"sync"
 )
 
 // HTTPFake struct to hold a 'fake' server
+//
+// RequestHandlers may be read directly while no requests are being served; use Handlers otherwise
 type HTTPFake struct {
-	server          *httptest.Server
-	RequestHandlers []*Request
+	server            *httptest.Server
+	RequestHandlers   []*Request
+	journal           *journal
+	mu                *sync.RWMutex
+	ca                *certAuthority
+	certificate       *tls.Certificate
+	customCertificate bool
+	clientCertificate *tls.Certificate
+	requireClientCert bool
+	h2c               bool
+	socketPath        string
+	fixtureDir        string
+	scenarios         map[string]string
 }
 
 // Server build a new fake server
 func Server() *HTTPFake {
 	server := &HTTPFake{
 		RequestHandlers: []*Request{},
+		journal:         &journal{},
+		scenarios:       map[string]string{},
+		mu:              &sync.RWMutex{},
 	}
 
 	server.server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		rh := server.findHandler(r)
-		if rh == nil {
+		body := readBody(r)
+		c, ties := server.findHandler(r, body)
+		for c != nil && !server.transition(c.handler) {
+			// a concurrent request moved the scenario on since routing: route again against the new state
+			c, ties = server.findHandler(r, body)
+		}
+		if c == nil {
+			server.journal.record(newRecordedRequest(r, body, nil))
 			w.WriteHeader(http.StatusNotFound)
+			if len(ties) > 0 {
+				w.Write([]byte(ambiguousMessage(r, ties)))
+				return
+			}
 			w.Write([]byte("--- 404 Page Not Found"))
 			return
 		}
-		if rh.CustomHandle != nil {
-			rh.CustomHandle(w, r, rh)
+		rh := c.handler
+		rr := newRecordedRequest(r, body, rh)
+		server.journal.record(rr)
+		rh.recordCall(rr)
+		response, ok := rh.nextResponse()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("--- 404 Response sequence exhausted for " + rh.String()))
+			return
+		}
+		r = withCall(r, &CallContext{Handler: rh, Params: c.params, Body: body, Response: response, FixtureDir: server.fixtures()})
+		if snapshot := response.snapshot(); snapshot.Fault != NoFault {
+			injectFault(w, r, snapshot)
 			return
 		}
-		DefaultResponder(w, r, rh)
+		rh.responder()(w, r, rh)
 	}))
 
 	return server
 }
 
-// Start the fake server
+// Start the fake server, panicking when it cannot listen; use port "0" for a random free port
 func (f *HTTPFake) Start(ip string, port string) *HTTPFake {
-	f.server.Listener = listener(ip, port)
-	f.server.Start()
+	if err := f.Listen(ip, port); err != nil {
+		panic(err)
+	}
 	return f
 }
 
+// StartAny start the fake server on a random free port of 127.0.0.1
+func (f *HTTPFake) StartAny() *HTTPFake {
+	return f.Start("127.0.0.1", "0")
+}
+
+// Listen start the fake server, returning an error when it cannot listen; use port "0" for a random free port
+func (f *HTTPFake) Listen(ip string, port string) error {
+	if f.server.URL != "" {
+		return errors.New("fakehttp: server already started on " + f.server.URL)
+	}
+	l, err := listener(ip, port)
+	if err != nil {
+		return err
+	}
+	f.setListener(l)
+	f.server.Start()
+	return nil
+}
+
 // Close the fake server
 func (f *HTTPFake) Close() {
 	f.server.Close()
+	f.removeSocket()
 }
 
 // URL of the fake server
@@ -55,68 +115,171 @@ func (f *HTTPFake) URL() string {
 	return f.server.URL
 }
 
-func listener(ip string, port string) net.Listener {
-	l, err := net.Listen("tcp", ip+":"+port)
+// Addr the host:port the fake server is bound to ("" before it is started)
+func (f *HTTPFake) Addr() string {
+	if f.server.URL == "" {
+		return ""
+	}
+	return f.server.Listener.Addr().String()
+}
+
+// Port the port the fake server is bound to ("" before it is started)
+func (f *HTTPFake) Port() string {
+	_, port, err := net.SplitHostPort(f.Addr())
 	if err != nil {
-		fmt.Println("--- TCP FAILED! Using TCP6! --- (err: " + err.Error())
-		if l, err = net.Listen("tcp6", "[::1]:0"); err != nil {
-			panic(fmt.Sprintf("httptest: failed to listen on a port: %v", err))
-		}
+		return ""
+	}
+	return port
+}
+
+// setListener swaps in l for the placeholder listener httptest opens on creation
+func (f *HTTPFake) setListener(l net.Listener) {
+	if f.server.Listener != nil {
+		f.server.Listener.Close()
+	}
+	f.server.Listener = l
+}
+
+func listener(ip string, port string) (net.Listener, error) {
+	l, err := net.Listen("tcp", net.JoinHostPort(ip, port))
+	if err != nil {
+		return nil, fmt.Errorf("fakehttp: failed to listen on %s: %v", net.JoinHostPort(ip, port), err)
 	}
-	return l
+	return l, nil
 }
 
 // NewHandler get a new request with a new handler
 func (f *HTTPFake) NewHandler(renderHTML bool) *Request {
 	rh := NewRequest(renderHTML)
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.RequestHandlers = append(f.RequestHandlers, rh)
 	return rh
 }
 
+// Handlers a snapshot of the registered Request Handlers, safe to use while requests are being served
+func (f *HTTPFake) Handlers() []*Request {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return append([]*Request{}, f.RequestHandlers...)
+}
+
 // ResolveURL return the url used to reach the fake server
 func (f *HTTPFake) ResolveURL(path string, args ...interface{}) string {
 	format := f.server.URL + path
 	return fmt.Sprintf(format, args...)
 }
 
-// Reset the fake server
+// Reset the fake server, forgetting the Request Handlers, the recorded requests and the scenario states
 func (f *HTTPFake) Reset() *HTTPFake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.RequestHandlers = []*Request{}
+	f.journal.reset()
+	f.scenarios = map[string]string{}
 	return f
 }
 
-func (f *HTTPFake) findHandler(r *http.Request) *Request {
-	founds := []*Request{}
-	url := r.URL.String()
-	path := getURLPath(url)
-	if !strings.HasSuffix(path, "/") {
-		path = path + "/"
-	}
-	for _, rh := range f.RequestHandlers {
-		if rh.Method != r.Method {
-			continue
-		}
+// Requests - every request received by the fake server, in arrival order
+func (f *HTTPFake) Requests() []*RecordedRequest {
+	return f.journal.filter(func(rr *RecordedRequest) bool { return true })
+}
 
-		rhURL, _ := netURL.QueryUnescape(rh.URL.String())
+// RequestsFor - the received requests that were routed to the given Request handler
+func (f *HTTPFake) RequestsFor(rh *Request) []*RecordedRequest {
+	return f.journal.filter(func(rr *RecordedRequest) bool { return rr.Matched == rh })
+}
 
-		if rhURL == url {
-			return rh
+// Verify - check the call count expectations of every Request handler, reporting all unmet ones in a single error
+func (f *HTTPFake) Verify() error {
+	failures := []string{}
+	for _, rh := range f.Handlers() {
+		if unmet := rh.unmetExpectation(); unmet != "" {
+			failures = append(failures, rh.String()+": "+unmet)
 		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("fakehttp: %d unmet expectation(s):\n  %s", len(failures), strings.Join(failures, "\n  "))
+}
+
+// UnmatchedRequests - the received requests no Request handler could answer
+func (f *HTTPFake) UnmatchedRequests() []*RecordedRequest {
+	return f.journal.filter(func(rr *RecordedRequest) bool { return rr.Matched == nil })
+}
+
+// pathKind - how a handler's path was matched, from least to most specific
+type pathKind int
 
-		if strings.HasPrefix(rhURL, "*") {
-			return rh
+const (
+	wildcardPath pathKind = iota
+	regexPath
+	templatePath
+	literalPath
+	queryPath
+	exactURL
+)
+
+// candidate - a handler whose method, path and conditions fit an incoming request
+type candidate struct {
+	handler    *Request
+	params     map[string]string
+	kind       pathKind
+	priority   int
+	conditions int
+}
+
+// findHandler returns the best scoring handler, or nil along with the tied handlers when no single one wins
+func (f *HTTPFake) findHandler(r *http.Request, body []byte) (*candidate, []*Request) {
+	founds := []*candidate{}
+	for _, rh := range f.Handlers() {
+		if c, ok := rh.match(r, body); ok && f.inScenarioState(rh) {
+			founds = append(founds, c)
 		}
+	}
+	return bestCandidate(founds)
+}
 
-		if getURLPath(rhURL) == path {
-			founds = append(founds, rh)
+// bestCandidate picks the highest scoring candidate, returning the tied handlers instead when there is no single winner
+func bestCandidate(founds []*candidate) (*candidate, []*Request) {
+	var best *candidate
+	ties := []*Request{}
+	for _, c := range founds {
+		switch cmp := compareScore(c, best); {
+		case cmp > 0:
+			best = c
+			ties = []*Request{c.handler}
+		case cmp == 0:
+			ties = append(ties, c.handler)
 		}
 	}
+	if len(ties) > 1 {
+		return nil, ties
+	}
+	return best, nil
+}
 
-	if len(founds) == 1 {
-		return founds[0]
+// compareScore orders candidates by explicit priority, then path kind, then number of conditions
+func compareScore(a, b *candidate) int {
+	if b == nil {
+		return 1
+	}
+	if a.priority != b.priority {
+		return a.priority - b.priority
+	}
+	if a.kind != b.kind {
+		return int(a.kind) - int(b.kind)
 	}
+	return a.conditions - b.conditions
+}
 
-	return nil
+func ambiguousMessage(r *http.Request, ties []*Request) string {
+	msg := fmt.Sprintf("--- 404 Ambiguous handlers for %s %s", r.Method, r.URL.String())
+	for _, rh := range ties {
+		msg += "\n---   " + rh.String()
+	}
+	return msg
 }
 
 func getURLPath(url string) string {
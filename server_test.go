@@ -6,6 +6,9 @@ import (
 
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"regexp"
+	"time"
 
 	. "github.com/khurlbut/fakehttp"
 )
@@ -33,6 +36,24 @@ var _ = Describe("HTTP Fake Tests", func() {
 		Ω(len(server.RequestHandlers)).Should(BeZero())
 	})
 
+	It("should expose the bound address and port", func() {
+		Ω(server.Addr()).Should(Equal(ip + ":" + port))
+		Ω(server.Port()).Should(Equal(port))
+		Ω(server.URL()).Should(Equal("http://" + ip + ":" + port))
+	})
+
+	It("should return an error when the port is already in use", func() {
+		other := Server()
+		err := other.Listen(ip, port)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("fakehttp: failed to listen on " + ip + ":" + port))
+		Ω(func() { other.Start(ip, port) }).Should(Panic())
+	})
+
+	It("should return an error when started twice", func() {
+		Ω(server.Listen(ip, "0")).Should(MatchError(ContainSubstring("already started")))
+	})
+
 	It("should add a new Request to the array of Request Handlers", func() {
 		r := server.NewHandler(false)
 		Ω(len(server.RequestHandlers)).ShouldNot(BeZero())
@@ -97,6 +118,314 @@ var _ = Describe("HTTP Fake Tests", func() {
 		Ω(res.StatusCode).Should(Equal(404))
 	})
 
+	It("should route on query parameters when handlers share a path", func() {
+		server.NewHandler(false).Get("/search").WithQuery("q", "a").Reply(200).BodyString("A")
+		server.NewHandler(false).Get("/search").WithQuery("q", "b").Reply(200).BodyString("B")
+
+		res, err := http.Get(server.ResolveURL("/search?q=b"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(res.StatusCode).Should(Equal(200))
+		Ω(string(body)).Should(Equal("B"))
+
+		res, err = http.Get(server.ResolveURL("/search?q=a"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ = ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal("A"))
+
+		res, err = http.Get(server.ResolveURL("/search?q=c"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		Ω(res.StatusCode).Should(Equal(404))
+	})
+
+	It("should match query parameters regardless of their order", func() {
+		server.NewHandler(false).Get("/search").WithQuery("q", "a").WithQuery("page", "2").Reply(200).BodyString("PAGE2")
+
+		res, err := http.Get(server.ResolveURL("/search?page=2&q=a"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal("PAGE2"))
+	})
+
+	It("should prefer the handler with the most query conditions", func() {
+		server.NewHandler(false).Get("/search").Reply(200).BodyString("ANY")
+		server.NewHandler(false).Get("/search").WithQueryMatcher("debug", MatchPresent, "").Reply(200).BodyString("DEBUG")
+		server.NewHandler(false).Get("/search").WithQueryMatcher("q", MatchRegex, "^[0-9]+$").WithQueryMatcher("debug", MatchAbsent, "").Reply(200).BodyString("NUMERIC")
+
+		res, err := http.Get(server.ResolveURL("/search?debug"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal("DEBUG"))
+
+		res, err = http.Get(server.ResolveURL("/search?q=42"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ = ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal("NUMERIC"))
+
+		res, err = http.Get(server.ResolveURL("/search?q=abc"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ = ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal("ANY"))
+	})
+
+	It("should capture path template parameters for the Responder", func() {
+		server.NewHandler(false).Get("/users/{id}/orders/{orderId}").Handle(func(w http.ResponseWriter, r *http.Request, rh *Request) {
+			params := Call(r).Params
+			w.Write([]byte(params["id"] + ":" + params["orderId"]))
+		})
+
+		res, err := http.Get(server.ResolveURL("/users/42/orders/7"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(res.StatusCode).Should(Equal(200))
+		Ω(string(body)).Should(Equal("42:7"))
+
+		res, err = http.Get(server.ResolveURL("/users/42/orders"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		Ω(res.StatusCode).Should(Equal(404))
+	})
+
+	It("should capture the remainder of the path with a trailing ** glob", func() {
+		server.NewHandler(false).Get("/files/**").Handle(func(w http.ResponseWriter, r *http.Request, rh *Request) {
+			w.Write([]byte(Call(r).Params["**"]))
+		})
+
+		res, err := http.Get(server.ResolveURL("/files/a/b/c.txt"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal("a/b/c.txt"))
+	})
+
+	It("should prefer a literal path over a path template", func() {
+		server.NewHandler(false).Get("/users/{id}").Reply(200).BodyString("TEMPLATE")
+		server.NewHandler(false).Get("/users/me").Reply(200).BodyString("LITERAL")
+
+		res, err := http.Get(server.ResolveURL("/users/me"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal("LITERAL"))
+
+		res, err = http.Get(server.ResolveURL("/users/99"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ = ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal("TEMPLATE"))
+	})
+
+	It("should serve a Response built as a literal", func() {
+		rh := server.NewHandler(false).Get("/literal")
+		rh.Response = &Response{StatusCode: 201, Header: http.Header{}, BodyBuffer: []byte("hi")}
+
+		res, err := http.Get(server.ResolveURL("/literal"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(res.StatusCode).Should(Equal(201))
+		Ω(string(body)).Should(Equal("hi"))
+	})
+
+	It("should let a Request and Response built as literals be configured", func() {
+		rh := &Request{URL: &url.URL{}, Header: http.Header{}, Response: &Response{Header: http.Header{}}}
+		rh.Get("/literal").Reply(202).SetHeader("X-Literal", "yes").Delay(time.Millisecond)
+		Ω(rh.String()).Should(Equal("GET /literal/"))
+		Ω(rh.Response.StatusCode).Should(Equal(202))
+		Ω(rh.Response.Header.Get("X-Literal")).Should(Equal("yes"))
+	})
+
+	It("should echo a captured path parameter through an injection key", func() {
+		fakeRequest := server.NewHandler(false).Get("/users/{id}").AddInjectionKey("param:id")
+		fakeRequest.Reply(200).BodyString(`{"id": "%s"}`)
+		fakeRequest.CustomHandle = SophisticatedResponder
+
+		res, err := http.Get(server.ResolveURL("/users/abc"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal(`{"id": "abc"}`))
+	})
+
+	It("should match a path against a PathRegex assigned directly", func() {
+		rh := &Request{Method: "GET", URL: &url.URL{}, Header: http.Header{}, PathRegex: regexp.MustCompile(`/v[0-9]+/(?P<item>[a-z]+)`)}
+		rh.Response = &Response{StatusCode: 200, Header: http.Header{}, BodyBuffer: []byte("LITERAL")}
+		server.RequestHandlers = append(server.RequestHandlers, rh)
+
+		res, err := http.Get(server.ResolveURL("/v3/widgets"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(res.StatusCode).Should(Equal(200))
+		Ω(string(body)).Should(Equal("LITERAL"))
+
+		res, err = http.Get(server.ResolveURL("/v3/widgets/extra"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		Ω(res.StatusCode).Should(Equal(404))
+	})
+
+	It("should match a path against a regular expression", func() {
+		server.NewHandler(false).GetRegex(`/v[0-9]+/items/.*`).Reply(200).BodyString("ITEMS")
+
+		res, err := http.Get(server.ResolveURL("/v2/items/a/b"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(res.StatusCode).Should(Equal(200))
+		Ω(string(body)).Should(Equal("ITEMS"))
+
+		res, err = http.Get(server.ResolveURL("/vX/items/a"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		Ω(res.StatusCode).Should(Equal(404))
+
+		res, err = http.Get(server.ResolveURL("/prefix/v2/items/a"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		Ω(res.StatusCode).Should(Equal(404))
+	})
+
+	It("should expose named regex capture groups to the Responder", func() {
+		server.NewHandler(false).Match("post", regexp.MustCompile(`/v(?P<version>[0-9]+)/items/(?P<item>[^/]+)`)).Handle(func(w http.ResponseWriter, r *http.Request, rh *Request) {
+			w.Write([]byte(Call(r).Params["version"] + "/" + Call(r).Params["item"]))
+		})
+
+		res, err := http.Post(server.ResolveURL("/v3/items/widget"), "text/plain", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal("3/widget"))
+	})
+
+	It("should prefer a path template over a regular expression", func() {
+		server.NewHandler(false).GetRegex(`/users/.*`).Reply(200).BodyString("REGEX")
+		server.NewHandler(false).Get("/users/{id}").Reply(200).BodyString("TEMPLATE")
+
+		res, err := http.Get(server.ResolveURL("/users/1"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal("TEMPLATE"))
+
+		res, err = http.Get(server.ResolveURL("/users/1/avatar"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ = ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal("REGEX"))
+	})
+
+	It("should prefer a specific path over a * handler registered earlier", func() {
+		server.NewHandler(false).Get("*").Reply(200).BodyString("ANY")
+		server.NewHandler(false).Get("/users").Reply(200).BodyString("USERS")
+
+		res, err := http.Get(server.ResolveURL("/users"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal("USERS"))
+
+		res, err = http.Get(server.ResolveURL("/other"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ = ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal("ANY"))
+	})
+
+	It("should let an explicit priority win over a more specific path", func() {
+		server.NewHandler(false).Get("/users").Reply(200).BodyString("USERS")
+		server.NewHandler(false).Get("*").Priority(10).Reply(503).BodyString("MAINTENANCE")
+
+		res, err := http.Get(server.ResolveURL("/users"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(res.StatusCode).Should(Equal(503))
+		Ω(string(body)).Should(Equal("MAINTENANCE"))
+	})
+
+	It("should report ambiguous handlers in the 404 body", func() {
+		server.NewHandler(false).Get("/users").Reply(200).BodyString("FIRST")
+		server.NewHandler(false).Get("/users").Reply(200).BodyString("SECOND")
+
+		res, err := http.Get(server.ResolveURL("/users"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(res.StatusCode).Should(Equal(404))
+		Ω(string(body)).Should(Equal("--- 404 Ambiguous handlers for GET /users\n---   GET /users/\n---   GET /users/"))
+	})
+
+	It("should resolve ambiguous handlers with an explicit priority", func() {
+		server.NewHandler(false).Get("/users").Reply(200).BodyString("FIRST")
+		server.NewHandler(false).Get("/users").Priority(1).Reply(200).BodyString("SECOND")
+
+		res, err := http.Get(server.ResolveURL("/users"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal("SECOND"))
+	})
+
+	It("should route on header conditions when handlers share a path", func() {
+		server.NewHandler(false).Get("/users").WithHeaderMatcher("accept", MatchContains, "json").Reply(200).BodyString("JSON")
+		server.NewHandler(false).Get("/users").WithHeaderMatcher("accept", MatchContains, "xml").Reply(200).BodyString("XML")
+		server.NewHandler(false).Get("/users").WithHeader("X-Api-Version", "2").Reply(200).BodyString("V2")
+		server.NewHandler(false).Get("/users").WithHeaderMatcher("Authorization", MatchAbsent, "").Reply(401)
+
+		get := func(key, val string) (int, string) {
+			req, _ := http.NewRequest("GET", server.ResolveURL("/users"), nil)
+			req.Header.Set("Authorization", "Bearer token")
+			if key != "" {
+				req.Header.Set(key, val)
+			}
+			res, err := http.DefaultClient.Do(req)
+			Ω(err).ShouldNot(HaveOccurred())
+			defer res.Body.Close()
+			body, _ := ioutil.ReadAll(res.Body)
+			return res.StatusCode, string(body)
+		}
+
+		_, body := get("Accept", "application/json")
+		Ω(body).Should(Equal("JSON"))
+		_, body = get("Accept", "application/xml")
+		Ω(body).Should(Equal("XML"))
+		_, body = get("x-api-version", "2")
+		Ω(body).Should(Equal("V2"))
+		status, _ := get("", "")
+		Ω(status).Should(Equal(404))
+
+		res, err := http.Get(server.ResolveURL("/users"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		Ω(res.StatusCode).Should(Equal(401))
+	})
+
+	It("should match header values against a regular expression", func() {
+		server.NewHandler(false).Get("/users").WithHeaderMatcher("Authorization", MatchRegex, `^Bearer [a-z]+$`).Reply(200)
+
+		req, _ := http.NewRequest("GET", server.ResolveURL("/users"), nil)
+		req.Header.Set("Authorization", "Bearer abc")
+		res, err := http.DefaultClient.Do(req)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		Ω(res.StatusCode).Should(Equal(200))
+
+		req.Header.Set("Authorization", "Basic abc")
+		res, err = http.DefaultClient.Do(req)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		Ω(res.StatusCode).Should(Equal(404))
+	})
+
 	It("should return 500 when using requires header handler without sending the headers", func() {
 		expected := "500: Required header Key:value not found!\nHeaders --> map[User-Agent:[Go-http-client/1.1] Accept-Encoding:[gzip]]"
 		fakeRequest := server.NewHandler(false).Get("/users").AddHeader("key", "value")
@@ -172,3 +501,22 @@ var _ = Describe("HTTP Fake Tests", func() {
 
 	})
 })
+
+var _ = Describe("HTTP Fake Random Port Tests", func() {
+	It("should start on a random free port", func() {
+		first := Server().StartAny()
+		defer first.Close()
+		second := Server().StartAny()
+		defer second.Close()
+
+		Ω(first.Port()).ShouldNot(Equal("0"))
+		Ω(first.Port()).ShouldNot(Equal(second.Port()))
+		Ω(first.URL()).Should(Equal("http://127.0.0.1:" + first.Port()))
+	})
+
+	It("should report no address before it is started", func() {
+		server := Server()
+		Ω(server.Addr()).Should(BeEmpty())
+		Ω(server.Port()).Should(BeEmpty())
+	})
+})
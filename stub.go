@@ -0,0 +1,283 @@
+package fakehttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Stub - the serializable definition of a Request handler and its Response, as loaded by LoadStubs
+//
+//	# users.yaml
+//	- request:
+//	    method: GET
+//	    path: /users/{id}
+//	    injectionKeys: [param:id]
+//	  response:
+//	    status: 200
+//	    headers: {Content-Type: application/json}
+//	    body: '{"id": "%s"}'
+//
+// Requests with headers, cookies, injection keys or service endpoints are answered by SophisticatedResponder
+type Stub struct {
+	Request  StubRequest  `json:"request" yaml:"request"`
+	Response StubResponse `json:"response" yaml:"response"`
+}
+
+// StubRequest - the serializable part of a Request
+type StubRequest struct {
+	Method           string            `json:"method" yaml:"method"`
+	Path             string            `json:"path" yaml:"path"`
+	PathRegex        string            `json:"pathRegex" yaml:"pathRegex"`
+	Query            map[string]string `json:"query" yaml:"query"`
+	Headers          map[string]string `json:"headers" yaml:"headers"`
+	Cookies          map[string]string `json:"cookies" yaml:"cookies"`
+	InjectionKeys    []string          `json:"injectionKeys" yaml:"injectionKeys"`
+	ServiceEndpoints []string          `json:"serviceEndpoints" yaml:"serviceEndpoints"`
+	Priority         int               `json:"priority" yaml:"priority"`
+	RenderHTML       bool              `json:"renderHTML" yaml:"renderHTML"`
+}
+
+// StubResponse - the serializable part of a Response; body, jsonBody and bodyFile are exclusive
+//
+// A relative bodyFile is resolved against the FixtureDir of the server when one is set, against the directory
+// of the stub file (see LoadStubs) otherwise
+type StubResponse struct {
+	Status   int               `json:"status" yaml:"status"`
+	Headers  map[string]string `json:"headers" yaml:"headers"`
+	Body     string            `json:"body" yaml:"body"`
+	JSONBody interface{}       `json:"jsonBody" yaml:"jsonBody"`
+	BodyFile string            `json:"bodyFile" yaml:"bodyFile"`
+	Template bool              `json:"template" yaml:"template"`
+}
+
+// LoadStubs register the stubs of a JSON or YAML file holding a list of Stub definitions, or a single one;
+// a YAML file may hold several documents separated by ---
+func (f *HTTPFake) LoadStubs(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("fakehttp: cannot load stubs: %v", err)
+	}
+	defer file.Close()
+	if err := f.loadStubs(file, filepath.Dir(path)); err != nil {
+		return fmt.Errorf("%v (in %s)", err, path)
+	}
+	return nil
+}
+
+// LoadStubsReader register the stubs read from r, JSON or YAML; none is registered when any is invalid
+func (f *HTTPFake) LoadStubsReader(r io.Reader) error {
+	return f.loadStubs(r, "")
+}
+
+// loadStubs registers the stubs read from r, resolving their relative body files against dir unless the
+// server has a FixtureDir
+func (f *HTTPFake) loadStubs(r io.Reader, dir string) error {
+	if f.fixtures() != "" {
+		dir = ""
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("fakehttp: cannot load stubs: %v", err)
+	}
+	stubs, err := decodeStubs(data)
+	if err != nil {
+		return fmt.Errorf("fakehttp: cannot load stubs: %v", err)
+	}
+	handlers := []*Request{}
+	for i, s := range stubs {
+		rh, err := s.handler(dir)
+		if err != nil {
+			return fmt.Errorf("fakehttp: invalid stub %d: %v", i+1, err)
+		}
+		handlers = append(handlers, rh)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.RequestHandlers = append(f.RequestHandlers, handlers...)
+	return nil
+}
+
+// LoadStubDir register the stubs of every .json, .yaml and .yml file of dir, in file name order; subdirectories
+// are not read, so the body files the stubs refer to can be kept there
+func (f *HTTPFake) LoadStubDir(dir string) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("fakehttp: cannot load stubs: %v", err)
+	}
+	names := []string{}
+	for _, e := range entries {
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".json", ".yaml", ".yml":
+			if !e.IsDir() {
+				names = append(names, e.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := f.LoadStubs(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeStubs decodes a list of stubs, or a single one, from JSON or YAML, rejecting unknown fields and
+// anything after the JSON value; every document of a multi-document YAML stream is decoded
+func decodeStubs(data []byte) ([]Stub, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return []Stub{}, nil
+	}
+	if trimmed[0] == '[' || trimmed[0] == '{' {
+		return decodeJSONStubs(trimmed)
+	}
+	return decodeYAMLStubs(data)
+}
+
+func decodeJSONStubs(data []byte) ([]Stub, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	stubs := []Stub{}
+	if data[0] == '{' {
+		var s Stub
+		if err := decoder.Decode(&s); err != nil {
+			return nil, err
+		}
+		stubs = append(stubs, s)
+	} else if err := decoder.Decode(&stubs); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the JSON value at offset %d", decoder.InputOffset())
+	}
+	return stubs, nil
+}
+
+// decodeYAMLStubs decodes each document of data, a list of stubs or a single one; a second decoder reads
+// the documents as nodes to tell which
+func decodeYAMLStubs(data []byte) ([]Stub, error) {
+	nodes := yaml.NewDecoder(bytes.NewReader(data))
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	stubs := []Stub{}
+	for document := 1; ; document++ {
+		var root yaml.Node
+		if err := nodes.Decode(&root); err == io.EOF {
+			return stubs, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("document %d: %v", document, err)
+		}
+		var err error
+		switch {
+		case len(root.Content) == 0 || root.Content[0].ShortTag() == "!!null":
+			// an empty document, such as one left by a trailing ---
+			err = decoder.Decode(&yaml.Node{})
+		case root.Content[0].Kind == yaml.SequenceNode:
+			list := []Stub{}
+			err = decoder.Decode(&list)
+			stubs = append(stubs, list...)
+		default:
+			var s Stub
+			err = decoder.Decode(&s)
+			stubs = append(stubs, s)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %v", document, err)
+		}
+	}
+}
+
+// handler builds the Request handler the stub defines, resolving a relative body file against dir when given
+func (s Stub) handler(dir string) (*Request, error) {
+	req, res := s.Request, s.Response
+	method := req.Method
+	if method == "" {
+		method = "GET"
+	}
+	rh := NewRequest(req.RenderHTML)
+	switch {
+	case req.Path != "" && req.PathRegex != "":
+		return nil, fmt.Errorf("both path and pathRegex given")
+	case req.PathRegex != "":
+		re, err := regexp.Compile(req.PathRegex)
+		if err != nil {
+			return nil, err
+		}
+		rh.Match(method, re)
+	case req.Path != "":
+		rh.method(method, req.Path)
+	default:
+		return nil, fmt.Errorf("no path or pathRegex given")
+	}
+	for k, v := range req.Query {
+		rh.WithQuery(k, v)
+	}
+	for k, v := range req.Headers {
+		rh.SetHeader(k, v)
+	}
+	for name, value := range req.Cookies {
+		rh.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+	for _, k := range req.InjectionKeys {
+		rh.AddInjectionKey(k)
+	}
+	for _, uri := range req.ServiceEndpoints {
+		rh.AddServiceEndpoint(uri)
+	}
+	rh.Priority(req.Priority)
+	if len(req.Headers)+len(req.Cookies)+len(req.InjectionKeys)+len(req.ServiceEndpoints) > 0 {
+		rh.Handle(SophisticatedResponder)
+	}
+
+	status := res.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := rh.Reply(status)
+	bodies := 0
+	if res.Body != "" {
+		bodies++
+		response.BodyString(res.Body)
+	}
+	if res.JSONBody != nil {
+		bodies++
+		b, err := json.Marshal(res.JSONBody)
+		if err != nil {
+			return nil, err
+		}
+		response.JSON(json.RawMessage(b))
+	}
+	if res.BodyFile != "" {
+		bodies++
+		path := res.BodyFile
+		if dir != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		response.BodyFile(path)
+	}
+	if bodies > 1 {
+		return nil, fmt.Errorf("only one of body, jsonBody and bodyFile may be given")
+	}
+	for k, v := range res.Headers {
+		response.SetHeader(k, v)
+	}
+	if res.Template {
+		if _, err := parseTemplate(res.Body); err != nil {
+			return nil, err
+		}
+		response.AsTemplate()
+	}
+	return rh, nil
+}
@@ -0,0 +1,206 @@
+package fakehttp_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+var _ = Describe("Stub Loading Tests", func() {
+	var server *HTTPFake
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "fakehttp-stubs")
+		Ω(err).ShouldNot(HaveOccurred())
+		server = Server().StartAny()
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		Ω(ioutil.WriteFile(path, []byte(content), 0644)).Should(Succeed())
+		return path
+	}
+
+	get := func(path string, header ...string) (*http.Response, string) {
+		req, err := http.NewRequest("GET", server.ResolveURL(path), nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		res, err := http.DefaultClient.Do(req)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		return res, string(body)
+	}
+
+	It("should load a list of stubs from a YAML file", func() {
+		path := write("users.yaml", `
+- request:
+    method: GET
+    path: /users/{id}
+    injectionKeys: [param:id]
+  response:
+    status: 200
+    headers:
+      Content-Type: application/json
+    body: '{"id": "%s"}'
+- request:
+    path: /health
+  response:
+    body: OK
+`)
+		Ω(server.LoadStubs(path)).Should(Succeed())
+
+		res, body := get("/users/7")
+		Ω(res.StatusCode).Should(Equal(200))
+		Ω(res.Header.Get("Content-Type")).Should(Equal("application/json"))
+		Ω(body).Should(Equal(`{"id": "7"}`))
+
+		_, body = get("/health")
+		Ω(body).Should(Equal("OK"))
+	})
+
+	It("should load a single stub from JSON, validating the required headers", func() {
+		stub := `{
+			"request": {"method": "get", "path": "/secure", "headers": {"X-Token": "t"}, "query": {"v": "2"}},
+			"response": {"status": 202, "jsonBody": {"ok": true}}
+		}`
+		Ω(server.LoadStubsReader(strings.NewReader(stub))).Should(Succeed())
+
+		res, body := get("/secure?v=2", "X-Token", "t")
+		Ω(res.StatusCode).Should(Equal(202))
+		Ω(res.Header.Get("Content-Type")).Should(Equal("application/json"))
+		Ω(body).Should(MatchJSON(`{"ok": true}`))
+
+		res, _ = get("/secure?v=2")
+		Ω(res.StatusCode).Should(Equal(500))
+	})
+
+	It("should load regular expression paths and templated bodies", func() {
+		stub := `
+request:
+  pathRegex: /v[0-9]+/items/.*
+response:
+  template: true
+  body: 'item {{index .Segments 2}}'
+`
+		Ω(server.LoadStubsReader(strings.NewReader(stub))).Should(Succeed())
+
+		_, body := get("/v2/items/abc")
+		Ω(body).Should(Equal("item abc"))
+	})
+
+	It("should load every stub file of a directory", func() {
+		write("a.json", `[{"request": {"path": "/a"}, "response": {"body": "A"}}]`)
+		write("b.yml", "request:\n  path: /b\nresponse:\n  body: B\n")
+		write("notes.txt", "not a stub")
+		Ω(server.LoadStubDir(dir)).Should(Succeed())
+
+		Ω(server.Handlers()).Should(HaveLen(2))
+		_, a := get("/a")
+		_, b := get("/b")
+		Ω(a).Should(Equal("A"))
+		Ω(b).Should(Equal("B"))
+	})
+
+	It("should resolve body files next to the stub file", func() {
+		Ω(os.Mkdir(filepath.Join(dir, "bodies"), 0755)).Should(Succeed())
+		write("bodies/user.json", `{"id": 1}`)
+		write("stubs.yaml", "request:\n  path: /user\nresponse:\n  bodyFile: bodies/user.json\n")
+		Ω(server.LoadStubDir(dir)).Should(Succeed())
+
+		res, body := get("/user")
+		Ω(res.StatusCode).Should(Equal(200))
+		Ω(res.Header.Get("Content-Type")).Should(Equal("application/json"))
+		Ω(body).Should(Equal(`{"id": 1}`))
+	})
+
+	It("should resolve body files against the fixture directory when one is set", func() {
+		fixtures, err := ioutil.TempDir("", "fakehttp-fixtures")
+		Ω(err).ShouldNot(HaveOccurred())
+		defer os.RemoveAll(fixtures)
+		Ω(ioutil.WriteFile(filepath.Join(fixtures, "user.json"), []byte(`{"id": 2}`), 0644)).Should(Succeed())
+		write("user.json", `{"id": 1}`)
+		path := write("stubs.yaml", "request:\n  path: /user\nresponse:\n  bodyFile: user.json\n")
+
+		server.FixtureDir(fixtures)
+		Ω(server.LoadStubs(path)).Should(Succeed())
+
+		_, body := get("/user")
+		Ω(body).Should(Equal(`{"id": 2}`))
+	})
+
+	It("should register no stub when one of them is invalid", func() {
+		stubs := `[{"request": {"path": "/ok"}}, {"request": {"pathRegex": "("}}]`
+		err := server.LoadStubsReader(strings.NewReader(stubs))
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("invalid stub 2"))
+		Ω(server.Handlers()).Should(BeEmpty())
+	})
+
+	It("should load every document of a multi-document YAML file", func() {
+		stubs := "request: {path: /c}\nresponse: {body: C}\n---\n- request: {path: /d}\n  response: {body: D}\n---\n"
+		Ω(server.LoadStubsReader(strings.NewReader(stubs))).Should(Succeed())
+
+		_, c := get("/c")
+		_, d := get("/d")
+		Ω(c).Should(Equal("C"))
+		Ω(d).Should(Equal("D"))
+	})
+
+	It("should report the document holding a misspelled field", func() {
+		stubs := "request: {path: /c}\n---\nrequest: {pth: /d}\n"
+		err := server.LoadStubsReader(strings.NewReader(stubs))
+		Ω(err).Should(MatchError(ContainSubstring("document 2")))
+		Ω(err).Should(MatchError(ContainSubstring("pth")))
+		Ω(server.Handlers()).Should(BeEmpty())
+	})
+
+	It("should reject data after the JSON value", func() {
+		stubs := `{"request": {"path": "/c"}} {"request": {"path": "/d"}}`
+		err := server.LoadStubsReader(strings.NewReader(stubs))
+		Ω(err).Should(MatchError(ContainSubstring("unexpected data after the JSON value")))
+		Ω(server.Handlers()).Should(BeEmpty())
+
+		err = server.LoadStubsReader(strings.NewReader(`[{"request": {"path": "/c"}}]]`))
+		Ω(err).Should(MatchError(ContainSubstring("unexpected data after the JSON value")))
+	})
+
+	It("should reject a misspelled field in YAML", func() {
+		stubs := "- request:\n    path: /a\n  response:\n    staus: 201\n"
+		err := server.LoadStubsReader(strings.NewReader(stubs))
+		Ω(err).Should(MatchError(ContainSubstring("staus")))
+		Ω(server.Handlers()).Should(BeEmpty())
+	})
+
+	It("should reject a misspelled field in JSON", func() {
+		err := server.LoadStubsReader(strings.NewReader(`{"request": {"pathRegx": "/a.*"}}`))
+		Ω(err).Should(MatchError(ContainSubstring("pathRegx")))
+	})
+
+	It("should reject a stub without a path", func() {
+		err := server.LoadStubsReader(strings.NewReader(`{"response": {"status": 200}}`))
+		Ω(err).Should(MatchError(ContainSubstring("no path or pathRegex given")))
+	})
+
+	It("should report the file of a stub it cannot parse", func() {
+		path := write("broken.json", `[{"request": `)
+		err := server.LoadStubs(path)
+		Ω(err).Should(MatchError(ContainSubstring("broken.json")))
+	})
+})
@@ -0,0 +1,146 @@
+package fakehttp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	mathrand "math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateData - what a templated body is rendered against
+//
+//	{{.Method}} {{.Path}} {{index .Segments 1}} {{.Query.Get "q"}} {{.Header.Get "X-Id"}}
+//	{{.Cookies.session}} {{.Params.id}} {{.Body}} {{jsonPath .JSON "$.items[0].name"}}
+type TemplateData struct {
+	Method   string
+	Path     string
+	Segments []string
+	Query    url.Values
+	Header   http.Header
+	Cookies  map[string]string
+	Params   map[string]string
+	Body     string
+	JSON     interface{}
+}
+
+// templateFuncs - the helper functions available to templated bodies
+var templateFuncs = template.FuncMap{
+	"uuid":      newUUID,
+	"now":       time.Now,
+	"randomInt": randomInt,
+	"jsonPath":  templateJSONPath,
+}
+
+// Template - answer with text rendered as a text/template against the incoming request (see TemplateData);
+// panics when text is not a valid template
+func (r *Response) Template(text string) *Response {
+	if _, err := parseTemplate(text); err != nil {
+		panic(err.Error())
+	}
+	r.BodyString(text)
+	return r.AsTemplate()
+}
+
+// AsTemplate - render the body, wherever it comes from, as a text/template against the incoming request
+func (r *Response) AsTemplate() *Response {
+	r.lock().Lock()
+	defer r.lock().Unlock()
+	r.Templated = true
+	return r
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	t, err := template.New("body").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("fakehttp: invalid body template: %v", err)
+	}
+	return t, nil
+}
+
+// render renders body when the response is templated, passing it through otherwise
+func (r *Response) render(body io.Reader, req *http.Request) (io.Reader, error) {
+	if !r.Templated {
+		return body, nil
+	}
+	text, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := renderTemplate(text, req)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(rendered), nil
+}
+
+// renderTemplate executes text as a text/template against the incoming request
+func renderTemplate(text []byte, req *http.Request) ([]byte, error) {
+	t, err := parseTemplate(string(text))
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, newTemplateData(req)); err != nil {
+		return nil, fmt.Errorf("fakehttp: cannot render body template: %v", err)
+	}
+	return out.Bytes(), nil
+}
+
+func newTemplateData(req *http.Request) *TemplateData {
+	call := Call(req)
+	body := call.Body
+	if body == nil {
+		body = readBody(req)
+	}
+	data := &TemplateData{
+		Method:   req.Method,
+		Path:     req.URL.Path,
+		Segments: splitPath(req.URL.Path),
+		Query:    req.URL.Query(),
+		Header:   req.Header,
+		Cookies:  map[string]string{},
+		Params:   call.Params,
+		Body:     string(body),
+	}
+	for _, c := range req.Cookies() {
+		data.Cookies[c.Name] = c.Value
+	}
+	json.Unmarshal(body, &data.JSON)
+	return data
+}
+
+// newUUID - a random (version 4) UUID
+func newUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// randomInt - a random integer in [min, max]
+func randomInt(min int, max int) int {
+	if max <= min {
+		return min
+	}
+	return min + mathrand.Intn(max-min+1)
+}
+
+// templateJSONPath resolves expr against doc, a decoded JSON document or JSON text
+func templateJSONPath(doc interface{}, expr string) interface{} {
+	if text, ok := doc.(string); ok {
+		if err := json.NewDecoder(strings.NewReader(text)).Decode(&doc); err != nil {
+			return nil
+		}
+	}
+	v, _ := jsonPath(doc, expr)
+	return v
+}
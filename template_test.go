@@ -0,0 +1,94 @@
+package fakehttp_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+var _ = Describe("Template Tests", func() {
+	var server *HTTPFake
+
+	BeforeEach(func() {
+		server = Server().StartAny()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	send := func(method string, path string, body string) (int, string) {
+		req, err := http.NewRequest(method, server.ResolveURL(path), strings.NewReader(body))
+		Ω(err).ShouldNot(HaveOccurred())
+		req.Header.Set("X-Request-Id", "r-1")
+		req.AddCookie(&http.Cookie{Name: "session", Value: "s-1"})
+		res, err := http.DefaultClient.Do(req)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		b, err := ioutil.ReadAll(res.Body)
+		Ω(err).ShouldNot(HaveOccurred())
+		return res.StatusCode, string(b)
+	}
+
+	It("should render the request into the body", func() {
+		server.NewHandler(false).Get("/users/{id}").Reply(200).Template(
+			`{{.Method}} {{.Path}} {{index .Segments 0}} {{.Params.id}} {{.Query.Get "q"}} {{.Header.Get "X-Request-Id"}} {{.Cookies.session}}`)
+
+		status, body := send("GET", "/users/42?q=x", "")
+		Ω(status).Should(Equal(200))
+		Ω(body).Should(Equal("GET /users/42 users 42 x r-1 s-1"))
+	})
+
+	It("should leave literal percent signs alone", func() {
+		server.NewHandler(false).Get("/discount").Reply(200).Template(`{"discount": "50%", "path": "{{.Path}}"}`)
+
+		_, body := send("GET", "/discount", "")
+		Ω(body).Should(MatchJSON(`{"discount": "50%", "path": "/discount"}`))
+	})
+
+	It("should expose the parsed JSON body", func() {
+		server.NewHandler(false).Post("/orders").Reply(201).Template(`{{.JSON.customer}} bought {{jsonPath .JSON "$.items[1].sku"}}; raw {{.Body}}`)
+
+		_, body := send("POST", "/orders", `{"customer": "ann", "items": [{"sku": "a"}, {"sku": "b"}]}`)
+		Ω(body).Should(Equal(`ann bought b; raw {"customer": "ann", "items": [{"sku": "a"}, {"sku": "b"}]}`))
+	})
+
+	It("should provide uuid, now and randomInt", func() {
+		server.NewHandler(false).Get("/generated").Reply(200).Template(`{{uuid}}|{{now.Year}}|{{randomInt 5 7}}`)
+
+		_, body := send("GET", "/generated", "")
+		parts := strings.Split(body, "|")
+		Ω(parts).Should(HaveLen(3))
+		Ω(parts[0]).Should(MatchRegexp(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`))
+		Ω(strconv.Atoi(parts[1])).Should(BeNumerically(">=", 2020))
+		Ω(strconv.Atoi(parts[2])).Should(BeNumerically("~", 6, 1))
+	})
+
+	It("should render templated bodies in the sophisticated responder", func() {
+		rh := server.NewHandler(false).Get("/users/{id}")
+		rh.Reply(200).Template(`user {{.Params.id}} at 100%`)
+		rh.AddInjectionKey("path")
+		rh.Handle(SophisticatedResponder)
+
+		_, body := send("GET", "/users/7", "")
+		Ω(body).Should(Equal("user 7 at 100%"))
+	})
+
+	It("should panic on an invalid template at definition time", func() {
+		Ω(func() { NewResponse().Template("{{.Path") }).Should(PanicWith(ContainSubstring("invalid body template")))
+	})
+
+	It("should answer 500 when the template cannot be rendered", func() {
+		server.NewHandler(false).Get("/broken").Reply(200).Template(`{{index .Segments 5}}`)
+
+		status, body := send("GET", "/broken", "")
+		Ω(status).Should(Equal(500))
+		Ω(body).Should(ContainSubstring("cannot render body template"))
+	})
+})
@@ -0,0 +1,152 @@
+package fakehttp
+
+import (
+	"strings"
+	"testing"
+)
+
+// Option - customizes the HTTPFake built by NewTestServer
+type Option func(*testOptions)
+
+type testOptions struct {
+	ip                string
+	allowUnmatched    bool
+	skipVerify        bool
+	tls               bool
+	requireClientCert bool
+	http2             bool
+	h2c               bool
+	socketPath        string
+}
+
+// WithIP - listen on ip instead of 127.0.0.1
+func WithIP(ip string) Option {
+	return func(o *testOptions) {
+		o.ip = ip
+	}
+}
+
+// WithTLS - serve HTTPS with a generated certificate; use HTTPFake.Client to reach it
+func WithTLS() Option {
+	return func(o *testOptions) {
+		o.tls = true
+	}
+}
+
+// WithClientCert - serve HTTPS and require a client certificate (mutual TLS); use HTTPFake.Client to reach it
+func WithClientCert() Option {
+	return func(o *testOptions) {
+		o.requireClientCert = true
+	}
+}
+
+// WithHTTP2 - serve HTTPS negotiating HTTP/2; use HTTPFake.Client to reach it
+func WithHTTP2() Option {
+	return func(o *testOptions) {
+		o.http2 = true
+	}
+}
+
+// WithH2C - serve cleartext HTTP/2 alongside HTTP/1.1; use HTTPFake.Client to reach it over h2c
+func WithH2C() Option {
+	return func(o *testOptions) {
+		o.h2c = true
+	}
+}
+
+// WithUnixSocket - listen on a unix domain socket at path; use HTTPFake.Client to reach it
+func WithUnixSocket(path string) Option {
+	return func(o *testOptions) {
+		o.socketPath = path
+	}
+}
+
+// AllowUnmatched - do not fail the test for requests no handler could answer
+func AllowUnmatched() Option {
+	return func(o *testOptions) {
+		o.allowUnmatched = true
+	}
+}
+
+// SkipVerify - do not fail the test for unmet call expectations
+func SkipVerify() Option {
+	return func(o *testOptions) {
+		o.skipVerify = true
+	}
+}
+
+// conflict describes options that cannot be honored together, "" when there are none
+func (o *testOptions) conflict() string {
+	secure := []string{}
+	if o.tls {
+		secure = append(secure, "WithTLS")
+	}
+	if o.requireClientCert {
+		secure = append(secure, "WithClientCert")
+	}
+	if o.http2 {
+		secure = append(secure, "WithHTTP2")
+	}
+	switch {
+	case o.socketPath != "" && len(secure) > 0:
+		return "fakehttp: WithUnixSocket cannot be combined with " + strings.Join(secure, ", ")
+	case o.h2c && len(secure) > 0:
+		return "fakehttp: WithH2C cannot be combined with " + strings.Join(secure, ", ")
+	}
+	return ""
+}
+
+// NewTestServer - start a fake server on a random port for the duration of a test
+//
+// The server is closed when the test finishes; unmatched requests and unmet call expectations
+// are then reported through t.Errorf, so failures surface in plain `go test`. Options that cannot be honored
+// together, such as WithUnixSocket and WithTLS, fail the test through t.Fatalf
+func NewTestServer(t testing.TB, opts ...Option) *HTTPFake {
+	t.Helper()
+	o := &testOptions{ip: "127.0.0.1"}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	f := Server()
+	if conflict := o.conflict(); conflict != "" {
+		t.Fatalf("%s", conflict)
+		return f
+	}
+	if o.requireClientCert {
+		f.RequireClientCert()
+	}
+	if o.http2 {
+		f.EnableHTTP2()
+	}
+	if o.h2c {
+		f.EnableH2C()
+	}
+	var err error
+	switch {
+	case o.socketPath != "":
+		err = f.ListenUnix(o.socketPath)
+	case o.tls || o.requireClientCert || o.http2:
+		err = f.ListenTLS(o.ip, "0")
+	default:
+		err = f.Listen(o.ip, "0")
+	}
+	if err != nil {
+		t.Fatalf("%v", err)
+		return f
+	}
+	t.Cleanup(func() {
+		f.Close()
+		if !o.allowUnmatched {
+			for _, rr := range f.UnmatchedRequests() {
+				t.Errorf("fakehttp: unmatched request %s", rr)
+			}
+		}
+		if !o.skipVerify {
+			if err := f.Verify(); err != nil {
+				t.Errorf("%v", err)
+			}
+		}
+	})
+	return f
+}
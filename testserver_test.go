@@ -0,0 +1,135 @@
+package fakehttp_test
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+var _ = Describe("Test Server Tests", func() {
+	var t *recordingTB
+
+	BeforeEach(func() {
+		t = &recordingTB{}
+	})
+
+	get := func(server *HTTPFake, path string) int {
+		res, err := http.Get(server.ResolveURL(path))
+		Ω(err).ShouldNot(HaveOccurred())
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	It("should start a server and close it on cleanup", func() {
+		server := NewTestServer(t)
+		server.NewHandler(false).Get("/users").Reply(200)
+		Ω(get(server, "/users")).Should(Equal(200))
+
+		t.cleanup()
+		Ω(t.errors).Should(BeEmpty())
+		_, err := http.Get(server.ResolveURL("/users"))
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("should report unmatched requests on cleanup", func() {
+		server := NewTestServer(t)
+		Ω(get(server, "/nowhere")).Should(Equal(404))
+
+		t.cleanup()
+		Ω(t.errors).Should(ConsistOf("fakehttp: unmatched request GET /nowhere (unmatched)"))
+	})
+
+	It("should report unmet call expectations on cleanup", func() {
+		server := NewTestServer(t)
+		server.NewHandler(false).Post("/payments").Times(1).Reply(201)
+
+		t.cleanup()
+		Ω(t.errors).Should(HaveLen(1))
+		Ω(t.errors[0]).Should(ContainSubstring("POST /payments/: expected exactly 1 call(s), got 0"))
+	})
+
+	It("should start a mutual TLS server", func() {
+		server := NewTestServer(t, WithClientCert())
+		server.NewHandler(false).Get("/users").Reply(200)
+
+		res, err := server.Client().Get(server.ResolveURL("/users"))
+		Ω(err).ShouldNot(HaveOccurred())
+		res.Body.Close()
+		Ω(res.StatusCode).Should(Equal(200))
+
+		t.cleanup()
+		Ω(t.errors).Should(BeEmpty())
+	})
+
+	It("should refuse TLS options on a unix socket", func() {
+		path := filepath.Join(os.TempDir(), "fakehttp-conflict.sock")
+		server := NewTestServer(t, WithUnixSocket(path), WithTLS(), WithHTTP2())
+
+		Ω(t.errors).Should(ConsistOf("fakehttp: WithUnixSocket cannot be combined with WithTLS, WithHTTP2"))
+		Ω(server.URL()).Should(BeEmpty())
+		Ω(t.cleanups).Should(BeEmpty())
+	})
+
+	It("should refuse h2c alongside TLS", func() {
+		NewTestServer(t, WithH2C(), WithClientCert())
+
+		Ω(t.errors).Should(ConsistOf("fakehttp: WithH2C cannot be combined with WithClientCert"))
+	})
+
+	It("should honour the options", func() {
+		server := NewTestServer(t, AllowUnmatched(), SkipVerify(), WithIP("127.0.0.1"))
+		server.NewHandler(false).Post("/payments").Times(1).Reply(201)
+		Ω(get(server, "/nowhere")).Should(Equal(404))
+
+		t.cleanup()
+		Ω(t.errors).Should(BeEmpty())
+	})
+})
+
+// recordingTB - a testing.TB capturing errors and cleanups instead of failing the running test
+type recordingTB struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (t *recordingTB) Helper() {}
+
+func (t *recordingTB) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *recordingTB) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingTB) Fatalf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingTB) cleanup() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func TestNewTestServerWithoutGinkgo(t *testing.T) {
+	server := NewTestServer(t)
+	server.NewHandler(false).Get("/users").Times(1).Reply(200)
+
+	res, err := http.Get(server.ResolveURL("/users"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != 200 {
+		t.Errorf("expected 200, got %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,212 @@
+package fakehttp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"time"
+)
+
+// certAuthority - a throwaway CA issuing the certificates of a TLS fake server and its clients
+type certAuthority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newCertAuthority() (*certAuthority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template, err := certTemplate("fakehttp CA")
+	if err != nil {
+		return nil, err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &certAuthority{cert: cert, key: key}, nil
+}
+
+// issue a certificate signed by the CA, for a server when hosts are given, for a client otherwise
+func (ca *certAuthority) issue(commonName string, hosts ...string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template, err := certTemplate(commonName)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	if len(hosts) > 0 {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if h != "" {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der, ca.cert.Raw}, PrivateKey: key, Leaf: leaf}, nil
+}
+
+func (ca *certAuthority) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+func certTemplate(commonName string) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"fakehttp"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}, nil
+}
+
+// StartTLS start the fake server over HTTPS, panicking when it cannot listen; use port "0" for a random free port
+func (f *HTTPFake) StartTLS(ip string, port string) *HTTPFake {
+	if err := f.ListenTLS(ip, port); err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// ListenTLS start the fake server over HTTPS, returning an error when it cannot listen
+//
+// Unless UseKeyPair was called, the server certificate is issued for ip, localhost, 127.0.0.1 and ::1
+// by a freshly generated CA available from CACert and CACertPool
+func (f *HTTPFake) ListenTLS(ip string, port string) error {
+	if f.server.URL != "" {
+		return errors.New("fakehttp: server already started on " + f.server.URL)
+	}
+	if f.ca == nil && (f.certificate == nil || f.requireClientCert) {
+		ca, err := newCertAuthority()
+		if err != nil {
+			return err
+		}
+		f.ca = ca
+	}
+	if f.certificate == nil {
+		cert, err := f.ca.issue("fakehttp server", ip, "localhost", "127.0.0.1", "::1")
+		if err != nil {
+			return err
+		}
+		f.certificate = &cert
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{*f.certificate}}
+	if f.requireClientCert {
+		cert, err := f.ca.issue("fakehttp client")
+		if err != nil {
+			return err
+		}
+		f.clientCertificate = &cert
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.ClientCAs = f.ca.pool()
+	}
+
+	l, err := listener(ip, port)
+	if err != nil {
+		return err
+	}
+	f.setListener(l)
+	f.server.TLS = config
+	f.server.StartTLS()
+	return nil
+}
+
+// RequireClientCert make a TLS fake server demand a client certificate issued by its CA (mutual TLS); call before starting
+func (f *HTTPFake) RequireClientCert() *HTTPFake {
+	f.requireClientCert = true
+	return f
+}
+
+// UseKeyPair serve TLS with the given PEM certificate and key instead of a generated one; call before starting
+func (f *HTTPFake) UseKeyPair(certFile string, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	f.certificate = &cert
+	f.customCertificate = true
+	return nil
+}
+
+// CACert the certificate of the generated CA issuing the server and client certificates, nil before ListenTLS
+func (f *HTTPFake) CACert() *x509.Certificate {
+	if f.ca == nil {
+		return nil
+	}
+	return f.ca.cert
+}
+
+// CACertPool a pool trusting the certificates served by the fake server
+func (f *HTTPFake) CACertPool() *x509.CertPool {
+	if f.ca != nil && !f.customCertificate {
+		return f.ca.pool()
+	}
+	pool := x509.NewCertPool()
+	if f.certificate != nil {
+		for _, der := range f.certificate.Certificate {
+			if cert, err := x509.ParseCertificate(der); err == nil {
+				pool.AddCert(cert)
+			}
+		}
+	}
+	return pool
+}
+
+// ClientCertificate the certificate a client must present when RequireClientCert is set, nil otherwise
+func (f *HTTPFake) ClientCertificate() *tls.Certificate {
+	return f.clientCertificate
+}
+
+// Client an *http.Client preconfigured to reach the fake server: trusting its certificate, presenting a
+// client certificate when one is required, speaking HTTP/2 when it is enabled and dialing its unix socket
+func (f *HTTPFake) Client() *http.Client {
+	if f.server.TLS == nil {
+		if f.h2c {
+			return h2cClient(f.dial)
+		}
+		if f.socketPath != "" {
+			return unixClient(f.dial)
+		}
+		return &http.Client{}
+	}
+	config := &tls.Config{RootCAs: f.CACertPool()}
+	if f.clientCertificate != nil {
+		config.Certificates = []tls.Certificate{*f.clientCertificate}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: config, ForceAttemptHTTP2: f.server.EnableHTTP2}}
+}
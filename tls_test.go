@@ -0,0 +1,140 @@
+package fakehttp_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+var _ = Describe("TLS Tests", func() {
+	var server *HTTPFake
+
+	BeforeEach(func() {
+		server = Server()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	get := func(client *http.Client) (string, error) {
+		res, err := client.Get(server.ResolveURL("/users"))
+		if err != nil {
+			return "", err
+		}
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		return string(body), nil
+	}
+
+	It("should serve HTTPS with a generated certificate", func() {
+		server.StartTLS("127.0.0.1", "0")
+		server.NewHandler(false).Get("/users").Reply(200).BodyString("SECURE")
+
+		Ω(server.URL()).Should(HavePrefix("https://127.0.0.1:"))
+		body, err := get(server.Client())
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(body).Should(Equal("SECURE"))
+
+		Ω(server.CACert()).ShouldNot(BeNil())
+		Ω(server.CACert().IsCA).Should(BeTrue())
+	})
+
+	It("should not be trusted by a client without the CA", func() {
+		server.StartTLS("127.0.0.1", "0")
+		server.NewHandler(false).Get("/users").Reply(200)
+
+		_, err := get(&http.Client{})
+		Ω(err).Should(HaveOccurred())
+
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: server.CACertPool()}}}
+		_, err = get(client)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should require a client certificate for mutual TLS", func() {
+		server.RequireClientCert().StartTLS("127.0.0.1", "0")
+		server.NewHandler(false).Get("/users").Reply(200).BodyString("MUTUAL")
+
+		Ω(server.ClientCertificate()).ShouldNot(BeNil())
+		body, err := get(server.Client())
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(body).Should(Equal("MUTUAL"))
+
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: server.CACertPool()}}}
+		_, err = get(client)
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("should serve a certificate loaded from files", func() {
+		dir, err := ioutil.TempDir("", "fakehttp")
+		Ω(err).ShouldNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		certFile, keyFile := writeSelfSignedKeyPair(dir)
+
+		Ω(server.UseKeyPair(certFile, keyFile)).Should(Succeed())
+		server.StartTLS("127.0.0.1", "0")
+		server.NewHandler(false).Get("/users").Reply(200).BodyString("CUSTOM")
+
+		body, err := get(server.Client())
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(body).Should(Equal("CUSTOM"))
+	})
+
+	It("should fail to load a missing key pair", func() {
+		Ω(server.UseKeyPair("missing.pem", "missing.key")).ShouldNot(Succeed())
+	})
+
+	It("should return a plain client for an HTTP server", func() {
+		server.StartAny()
+		server.NewHandler(false).Get("/users").Reply(200).BodyString("PLAIN")
+
+		body, err := get(server.Client())
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(body).Should(Equal("PLAIN"))
+		Ω(strings.HasPrefix(server.URL(), "http://")).Should(BeTrue())
+	})
+})
+
+func writeSelfSignedKeyPair(dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Ω(err).ShouldNot(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "custom"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Ω(err).ShouldNot(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Ω(err).ShouldNot(HaveOccurred())
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	Ω(ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)).Should(Succeed())
+	Ω(ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)).Should(Succeed())
+	return certFile, keyFile
+}
@@ -0,0 +1,70 @@
+package fakehttp
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+)
+
+// unixURL - the URL of a fake server listening on a unix socket; its host is only a placeholder
+const unixURL = "http://unix"
+
+// StartUnix start the fake server on a unix domain socket, panicking when it cannot listen
+func (f *HTTPFake) StartUnix(path string) *HTTPFake {
+	if err := f.ListenUnix(path); err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// ListenUnix start the fake server on a unix domain socket, returning an error when it cannot listen
+//
+// A stale socket left at path is replaced, and the socket is removed on Close. URL returns
+// http://unix, whose host is ignored by the *http.Client from Client, which dials the socket
+func (f *HTTPFake) ListenUnix(path string) error {
+	if f.server.URL != "" {
+		return errors.New("fakehttp: server already started on " + f.server.URL)
+	}
+	l, err := unixListener(path)
+	if err != nil {
+		return err
+	}
+	f.socketPath = path
+	f.setListener(l)
+	f.server.Start()
+	f.server.URL = unixURL
+	return nil
+}
+
+// dial connects to the fake server, through its unix socket when it has one
+func (f *HTTPFake) dial(network, addr string) (net.Conn, error) {
+	if f.socketPath != "" {
+		return net.Dial("unix", f.socketPath)
+	}
+	return net.Dial(network, addr)
+}
+
+func (f *HTTPFake) removeSocket() {
+	if f.socketPath == "" {
+		return
+	}
+	if fi, err := os.Lstat(f.socketPath); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		os.Remove(f.socketPath)
+	}
+}
+
+func unixListener(path string) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, errors.New("fakehttp: failed to listen on unix socket " + path + ": " + err.Error())
+	}
+	return l, nil
+}
+
+func unixClient(dial func(network, addr string) (net.Conn, error)) *http.Client {
+	return &http.Client{Transport: &http.Transport{Dial: dial}}
+}
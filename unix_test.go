@@ -0,0 +1,84 @@
+package fakehttp_test
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/khurlbut/fakehttp"
+)
+
+var _ = Describe("Unix Socket Tests", func() {
+	var server *HTTPFake
+	var dir string
+	var socket string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "fakehttp")
+		Ω(err).ShouldNot(HaveOccurred())
+		socket = filepath.Join(dir, "fake.sock")
+		server = Server()
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	It("should serve requests over a unix socket", func() {
+		server.StartUnix(socket)
+		server.NewHandler(false).Get("/users").Reply(200).BodyString("SOCKET")
+
+		Ω(server.URL()).Should(Equal("http://unix"))
+		Ω(server.Addr()).Should(Equal(socket))
+
+		res, err := server.Client().Get(server.ResolveURL("/users"))
+		Ω(err).ShouldNot(HaveOccurred())
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		Ω(string(body)).Should(Equal("SOCKET"))
+	})
+
+	It("should remove the socket file on Close", func() {
+		server.StartUnix(socket)
+		_, err := os.Stat(socket)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		server.Close()
+		_, err = os.Stat(socket)
+		Ω(os.IsNotExist(err)).Should(BeTrue())
+	})
+
+	It("should replace a stale socket", func() {
+		l, err := net.Listen("unix", socket)
+		Ω(err).ShouldNot(HaveOccurred())
+		if ul, ok := l.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+		l.Close()
+
+		Ω(server.ListenUnix(socket)).Should(Succeed())
+	})
+
+	It("should not replace a regular file", func() {
+		Ω(ioutil.WriteFile(socket, []byte("data"), 0600)).Should(Succeed())
+		Ω(server.ListenUnix(socket)).ShouldNot(Succeed())
+		data, _ := ioutil.ReadFile(socket)
+		Ω(string(data)).Should(Equal("data"))
+	})
+
+	It("should speak h2c over a unix socket", func() {
+		server.EnableH2C().StartUnix(socket)
+		server.NewHandler(false).Get("/users").Reply(200)
+
+		res, err := server.Client().Get(server.ResolveURL("/users"))
+		Ω(err).ShouldNot(HaveOccurred())
+		res.Body.Close()
+		Ω(res.ProtoMajor).Should(Equal(2))
+	})
+})